@@ -2,12 +2,44 @@ package binding
 
 import "net/http"
 
+const (
+	MIMEJSON  = "application/json"
+	MIMEHTML  = "text/html"
+	MIMEXML   = "application/xml"
+	MIMEXML2  = "text/xml"
+	MIMEPlain = "text/plain"
+
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+)
+
 type Binding interface {
 	Name() string
 	Bind(*http.Request, any) error
 }
 
+// BindingBody binds a request body already read, so the same body can be
+// bound several times.
+type BindingBody interface {
+	Binding
+	BindBody([]byte, any) error
+}
+
+// BindingUri binds the path parameters of the route, which are not part of
+// the *http.Request.
+type BindingUri interface {
+	Name() string
+	BindUri(map[string][]string, any) error
+}
+
 var (
 	JSON = &jsonBinding{}
 	XML  = &xmlBinding{}
+
+	Form          = &formBinding{}
+	FormPost      = &formPostBinding{}
+	FormMultipart = &formMultipartBinding{}
+	Query         = &queryBinding{}
+	Header        = &headerBinding{}
+	Uri           = &uriBinding{}
 )
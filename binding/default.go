@@ -0,0 +1,52 @@
+package binding
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Binding{
+		MIMEJSON: JSON,
+		MIMEXML:  XML,
+		MIMEXML2: XML,
+
+		MIMEPOSTForm:          Form,
+		MIMEMultipartPOSTForm: FormMultipart,
+	}
+)
+
+// Register makes Default return b for the requests of contentType. It
+// replaces the binding registered before for the same media type.
+func Register(contentType string, b Binding) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[mediaType(contentType)] = b
+}
+
+// Default returns the binding for a request of the given method and
+// Content-Type, or nil if the media type is not supported. GET requests
+// are bound from their query with Form.
+func Default(method string, contentType string) Binding {
+	if method == http.MethodGet {
+		return Form
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[mediaType(contentType)]
+}
+
+func mediaType(contentType string) string {
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	parsed, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(parsed))
+}
@@ -0,0 +1,50 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	tests := []struct {
+		method      string
+		contentType string
+		want        Binding
+	}{
+		{http.MethodGet, MIMEJSON, Form},
+		{http.MethodPost, MIMEJSON, JSON},
+		{http.MethodPost, "Application/JSON; charset=utf-8", JSON},
+		{http.MethodPut, MIMEXML, XML},
+		{http.MethodPost, MIMEXML2, XML},
+		{http.MethodPost, MIMEPOSTForm, Form},
+		{http.MethodPost, MIMEMultipartPOSTForm + "; boundary=x", FormMultipart},
+		{http.MethodPost, MIMEPlain, nil},
+		{http.MethodPost, "", nil},
+		{http.MethodPost, "application/json;;", JSON},
+	}
+	for _, tt := range tests {
+		if got := Default(tt.method, tt.contentType); got != tt.want {
+			t.Errorf("Default(%s, %q) = %v, want %v", tt.method, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+type testBinding struct{}
+
+func (testBinding) Name() string { return "test" }
+
+func (testBinding) Bind(*http.Request, any) error { return nil }
+
+func TestRegister(t *testing.T) {
+	var b Binding = testBinding{}
+	Register("application/x-test; charset=utf-8", b)
+	if got := Default(http.MethodPost, "application/x-test"); got != b {
+		t.Errorf("Default after Register = %v, want the registered binding", got)
+	}
+
+	Register(MIMEJSON, b)
+	defer Register(MIMEJSON, JSON)
+	if got := Default(http.MethodPost, MIMEJSON); got != b {
+		t.Errorf("Default after replacing JSON = %v, want the registered binding", got)
+	}
+}
@@ -0,0 +1,82 @@
+package binding
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+)
+
+const defaultMemory = 32 << 20 // 32M
+
+type formBinding struct {
+}
+
+type formPostBinding struct {
+}
+
+type formMultipartBinding struct {
+}
+
+func (f *formBinding) Name() string {
+	return "form"
+}
+
+// Bind maps the query and the body form of r, the uploaded files included
+// for a multipart body, to the form tags of obj.
+func (f *formBinding) Bind(r *http.Request, obj any) error {
+	if err := r.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	if err := mapByTag(obj, "form", lookupValues(r.Form), lookupFiles(r.MultipartForm)); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+func (f *formPostBinding) Name() string {
+	return "form-urlencoded"
+}
+
+// Bind maps the body form of r to the form tags of obj, ignoring the query.
+func (f *formPostBinding) Bind(r *http.Request, obj any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	if err := mapForm(obj, r.PostForm); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+func (f *formMultipartBinding) Name() string {
+	return "multipart/form-data"
+}
+
+// Bind maps the values and files of the multipart body of r to the form
+// tags of obj.
+func (f *formMultipartBinding) Bind(r *http.Request, obj any) error {
+	if err := r.ParseMultipartForm(defaultMemory); err != nil {
+		return err
+	}
+	if err := mapByTag(obj, "form", lookupValues(r.MultipartForm.Value), lookupFiles(r.MultipartForm)); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+func lookupValues(values map[string][]string) valuesLookup {
+	return func(key string) ([]string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+}
+
+func lookupFiles(form *multipart.Form) filesLookup {
+	if form == nil {
+		return nil
+	}
+	return func(key string) ([]*multipart.FileHeader, bool) {
+		files, ok := form.File[key]
+		return files, ok
+	}
+}
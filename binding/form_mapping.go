@@ -0,0 +1,322 @@
+package binding
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errNotPointer = errors.New("binding: the argument must be a non-nil pointer to a struct")
+
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+// valuesLookup returns the values of key in the request source.
+type valuesLookup func(key string) ([]string, bool)
+
+// filesLookup returns the uploaded files of key.
+type filesLookup func(key string) ([]*multipart.FileHeader, bool)
+
+func mapForm(ptr any, form map[string][]string) error {
+	return mapFormByTag(ptr, form, "form")
+}
+
+func mapFormByTag(ptr any, form map[string][]string, tag string) error {
+	return mapByTag(ptr, tag, lookupValues(form), nil)
+}
+
+// mapByTag sets the fields of the struct ptr points to from the values
+// found under the name given by their tag, or their Go name without tag.
+// The tag can also give a default value, e.g. `form:"page,default=1"`.
+// Embedded structs and struct fields without value are mapped
+// recursively from the same source.
+func mapByTag(ptr any, tag string, values valuesLookup, files filesLookup) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errNotPointer
+	}
+	m := &mapper{tag: tag, values: values, files: files, visiting: make(map[reflect.Type]bool)}
+	_, err := m.mapStruct(v.Elem())
+	return err
+}
+
+type mapper struct {
+	tag    string
+	values valuesLookup
+	files  filesLookup
+	// visiting holds the struct types being mapped, so a struct pointing
+	// back to its own type, like a linked list node, is not walked forever.
+	visiting map[reflect.Type]bool
+}
+
+// mapStruct sets the fields of v and reports whether one of them was set.
+func (m *mapper) mapStruct(v reflect.Value) (bool, error) {
+	t := v.Type()
+	if m.visiting[t] {
+		return false, nil
+	}
+	m.visiting[t] = true
+	defer delete(m.visiting, t)
+
+	isSet := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, opts := parseTag(field.Tag.Get(m.tag))
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		ok, err := m.mapField(v.Field(i), field, name, opts)
+		if err != nil {
+			return false, fmt.Errorf("binding: field %s: %w", field.Name, err)
+		}
+		isSet = isSet || ok
+	}
+	return isSet, nil
+}
+
+// mapField sets value from the source and reports whether it was set.
+func (m *mapper) mapField(value reflect.Value, field reflect.StructField, name string, opts tagOptions) (bool, error) {
+	if field.Type == fileHeaderType || field.Type == reflect.SliceOf(fileHeaderType) {
+		return m.mapFiles(value, name)
+	}
+
+	if value.Kind() == reflect.Pointer {
+		// Like encoding/json, skip the embedded pointers to unexported
+		// structs, which can not be allocated.
+		if !value.CanSet() {
+			return false, nil
+		}
+		elem := reflect.New(value.Type().Elem())
+		ok, err := m.mapField(elem.Elem(), field, name, opts)
+		if ok {
+			value.Set(elem)
+		}
+		return ok, err
+	}
+
+	values, ok := m.values(name)
+	if !ok && opts.hasDefault {
+		values, ok = []string{opts.defaultValue}, true
+	}
+
+	if value.Kind() == reflect.Struct && value.Type() != timeType && !implementsTextUnmarshaler(value) {
+		if ok && !field.Anonymous {
+			return false, fmt.Errorf("can not set struct from %q", name)
+		}
+		// The exported fields of an embedded unexported struct are still
+		// settable one by one.
+		return m.mapStruct(value)
+	}
+	if !ok || !value.CanSet() {
+		return false, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(value.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := setValue(slice.Index(i), field, s); err != nil {
+				return false, err
+			}
+		}
+		value.Set(slice)
+	case reflect.Array:
+		if len(values) != value.Len() {
+			return false, fmt.Errorf("%q has %d values, %d expected", name, len(values), value.Len())
+		}
+		for i, s := range values {
+			if err := setValue(value.Index(i), field, s); err != nil {
+				return false, err
+			}
+		}
+	default:
+		if len(values) == 0 {
+			return false, nil
+		}
+		if err := setValue(value, field, values[0]); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (m *mapper) mapFiles(value reflect.Value, name string) (bool, error) {
+	if m.files == nil {
+		return false, nil
+	}
+	files, ok := m.files(name)
+	if !ok || len(files) == 0 {
+		return false, nil
+	}
+	if value.Kind() == reflect.Slice {
+		value.Set(reflect.ValueOf(files))
+	} else {
+		value.Set(reflect.ValueOf(files[0]))
+	}
+	return true, nil
+}
+
+func implementsTextUnmarshaler(value reflect.Value) bool {
+	return value.CanAddr() && value.Addr().Type().Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// setValue parses s into value, which is a single element: a scalar, a
+// time.Time or an encoding.TextUnmarshaler.
+func setValue(value reflect.Value, field reflect.StructField, s string) error {
+	if value.Kind() == reflect.Pointer {
+		elem := reflect.New(value.Type().Elem())
+		if err := setValue(elem.Elem(), field, s); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	if value.Type() == timeType {
+		return setTime(value, field, s)
+	}
+	if implementsTextUnmarshaler(value) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if value.Type() == durationType {
+		if s == "" {
+			s = "0"
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+		return nil
+	case reflect.Interface:
+		value.Set(reflect.ValueOf(s))
+		return nil
+	}
+
+	if s == "" {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// setTime parses s with the layout of the time_format tag, RFC 3339 by
+// default, or as a Unix time if time_format is unix, unixmilli or unixnano.
+// The time_utc and time_location tags set the location of the time.
+func setTime(value reflect.Value, field reflect.StructField, s string) error {
+	if s == "" {
+		value.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+
+	layout := field.Tag.Get("time_format")
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	switch layout {
+	case "unix", "unixmilli", "unixnano":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		t := time.Unix(n, 0)
+		if layout == "unixmilli" {
+			t = time.UnixMilli(n)
+		} else if layout == "unixnano" {
+			t = time.Unix(0, n)
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	location := time.Local
+	if utc, _ := strconv.ParseBool(field.Tag.Get("time_utc")); utc {
+		location = time.UTC
+	}
+	if name := field.Tag.Get("time_location"); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return err
+		}
+		location = loc
+	}
+
+	t, err := time.ParseInLocation(layout, s, location)
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(t))
+	return nil
+}
+
+type tagOptions struct {
+	hasDefault   bool
+	defaultValue string
+}
+
+// parseTag splits a tag such as "name,default=5" into the name and options.
+func parseTag(tag string) (string, tagOptions) {
+	name, rest, _ := strings.Cut(tag, ",")
+	var opts tagOptions
+	for rest != "" {
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		if strings.HasPrefix(opt, "default=") {
+			opts.hasDefault = true
+			opts.defaultValue = strings.TrimPrefix(opt, "default=")
+		}
+	}
+	return name, opts
+}
@@ -0,0 +1,201 @@
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type inner struct {
+	A int `form:"a"`
+}
+
+type Embedded struct {
+	E string `form:"e"`
+}
+
+type node struct {
+	Value string `form:"value"`
+	Next  *node
+}
+
+type formTarget struct {
+	Embedded
+	List     node
+	Self     *formTarget
+	Name     string        `form:"name"`
+	Age      *int          `form:"age"`
+	Missing  *int          `form:"missing"`
+	Score    float64       `form:"score"`
+	OK       bool          `form:"ok"`
+	Tags     []string      `form:"tag"`
+	Nums     []int         `form:"n"`
+	Pair     [2]uint8      `form:"pair"`
+	Page     int           `form:"page,default=1"`
+	Timeout  time.Duration `form:"timeout"`
+	Day      time.Time     `form:"day" time_format:"2006-01-02" time_utc:"1"`
+	Unix     time.Time     `form:"unix" time_format:"unix"`
+	Skipped  string        `form:"-"`
+	NoTag    string
+	internal string
+}
+
+func TestMapForm(t *testing.T) {
+	form := url.Values{
+		"e":       {"embedded"},
+		"name":    {"sonata"},
+		"age":     {"7"},
+		"score":   {"1.5"},
+		"ok":      {"true"},
+		"tag":     {"a", "b"},
+		"n":       {"1", "2"},
+		"pair":    {"3", "4"},
+		"timeout": {"2s"},
+		"day":     {"2024-01-02"},
+		"unix":    {"1700000000"},
+		"Skipped": {"x"},
+		"-":       {"x"},
+		"NoTag":   {"no tag"},
+		"value":   {"head"},
+	}
+	var got formTarget
+	if err := mapForm(&got, form); err != nil {
+		t.Fatal(err)
+	}
+
+	age := 7
+	want := formTarget{
+		Embedded: Embedded{E: "embedded"},
+		Name:     "sonata",
+		Age:      &age,
+		Score:    1.5,
+		OK:       true,
+		Tags:     []string{"a", "b"},
+		Nums:     []int{1, 2},
+		Pair:     [2]uint8{3, 4},
+		Page:     1,
+		Timeout:  2 * time.Second,
+		Day:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Unix:     time.Unix(1700000000, 0),
+		NoTag:    "no tag",
+		List:     node{Value: "head"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mapForm =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestMapFormErrors(t *testing.T) {
+	tests := []struct {
+		form url.Values
+		err  string
+	}{
+		{url.Values{"age": {"x"}}, "field Age"},
+		{url.Values{"pair": {"1"}}, "field Pair"},
+		{url.Values{"day": {"02/01/2024"}}, "field Day"},
+	}
+	for _, tt := range tests {
+		var got formTarget
+		err := mapForm(&got, tt.form)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("mapForm(%v) = %v, want an error about %s", tt.form, err, tt.err)
+		}
+	}
+
+	if err := mapForm(formTarget{}, nil); err != errNotPointer {
+		t.Errorf("mapForm(struct) = %v, want %v", err, errNotPointer)
+	}
+}
+
+func TestMapFormEmbeddedUnexported(t *testing.T) {
+	var byValue struct {
+		inner
+		B int `form:"b"`
+	}
+	if err := mapForm(&byValue, url.Values{"a": {"1"}, "b": {"2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if byValue.A != 1 || byValue.B != 2 {
+		t.Errorf("got A=%d B=%d, want A=1 B=2", byValue.A, byValue.B)
+	}
+
+	var byPointer struct {
+		*inner
+		B int `form:"b"`
+	}
+	r := httptest.NewRequest(http.MethodGet, "/?a=1&b=2", nil)
+	if err := Form.Bind(r, &byPointer); err != nil {
+		t.Fatal(err)
+	}
+	if byPointer.inner != nil || byPointer.B != 2 {
+		t.Errorf("got inner=%v B=%d, want inner=nil B=2", byPointer.inner, byPointer.B)
+	}
+}
+
+func TestFormMultipartBinding(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("name", "upload")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		fw, _ := mw.CreateFormFile("files", name)
+		fw.Write([]byte(name))
+	}
+	mw.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var got struct {
+		Name  string                  `form:"name"`
+		File  *multipart.FileHeader   `form:"files"`
+		Files []*multipart.FileHeader `form:"files"`
+	}
+	if err := FormMultipart.Bind(r, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "upload" || got.File == nil || got.File.Filename != "a.txt" || len(got.Files) != 2 {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestFormPostBindingIgnoresQuery(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/?name=query", strings.NewReader("age=3"))
+	r.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var got formTarget
+	if err := FormPost.Bind(r, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "" || got.Age == nil || *got.Age != 3 {
+		t.Errorf("got Name=%q Age=%v, want the body only", got.Name, got.Age)
+	}
+}
+
+func TestFormBindingValidates(t *testing.T) {
+	var got struct {
+		Name string `form:"name" validate:"required"`
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, ok := Form.Bind(r, &got).(ValidationErrors); !ok {
+		t.Errorf("Form.Bind without name should return ValidationErrors")
+	}
+}
+
+func TestFormPostBindingRecursiveType(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("value=a"))
+	r.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var got node
+	if err := FormPost.Bind(r, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Value != "a" || got.Next != nil {
+		t.Errorf("got %+v, want Value a and no Next", got)
+	}
+}
@@ -0,0 +1,26 @@
+package binding
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+type headerBinding struct {
+}
+
+func (h *headerBinding) Name() string {
+	return "header"
+}
+
+// Bind maps the headers of r to the header tags of obj. The tag names are
+// case insensitive, like the header names.
+func (h *headerBinding) Bind(r *http.Request, obj any) error {
+	lookup := func(key string) ([]string, bool) {
+		values, ok := r.Header[textproto.CanonicalMIMEHeaderKey(key)]
+		return values, ok
+	}
+	if err := mapByTag(obj, "header", lookup, nil); err != nil {
+		return err
+	}
+	return validate(obj)
+}
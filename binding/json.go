@@ -1,16 +1,18 @@
 package binding
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"io"
 	"net/http"
-	"reflect"
 )
 
 type jsonBinding struct {
 	DisallowUnknownFields bool
-	IsValidate            bool
+	// IsValidate enables the check of the fields tagged sonata:"required",
+	// which must be present and not null in the body.
+	IsValidate bool
 }
 
 func (j *jsonBinding) Name() string {
@@ -18,94 +20,49 @@ func (j *jsonBinding) Name() string {
 }
 
 func (j *jsonBinding) Bind(r *http.Request, obj any) error {
-	body := r.Body
-	if body == nil {
+	if r.Body == nil {
 		return errors.New("invalid request")
 	}
-	decoder := json.NewDecoder(body)
-	if j.DisallowUnknownFields {
-		decoder.DisallowUnknownFields()
-	}
-	if j.IsValidate {
-		err := validateParam(obj, decoder)
-		if err != nil {
-			return err
-		}
-	} else {
-		err := decoder.Decode(obj)
-		if err != nil {
-			return err
-		}
-	}
-	return validate(obj)
+	return j.bind(r.Body, obj)
 }
 
-func validateParam(obj any, decoder *json.Decoder) error {
-	v := reflect.ValueOf(obj)
-	if v.Kind() != reflect.Pointer {
-		return errors.New("this argument must have a pointer type")
-	}
-	elem := v.Elem().Interface()
-	of := reflect.ValueOf(elem)
-
-	switch of.Kind() {
-	case reflect.Struct:
-		return checkParam(of, obj, decoder)
-	case reflect.Slice, reflect.Array:
-		elem := of.Type().Elem()
-		if elem.Kind() == reflect.Struct {
-			return checkParamSlice(elem, obj, decoder)
-		}
-	default:
-		decoder.Decode(obj)
-	}
-
-	return nil
+func (j *jsonBinding) BindBody(body []byte, obj any) error {
+	return j.bind(bytes.NewReader(body), obj)
 }
 
-func checkParamSlice(of reflect.Type, obj any, decoder *json.Decoder) error {
-	mapValue := make([]map[string]any, 0)
-	_ = decoder.Decode(&mapValue)
-	for i := 0; i < of.NumField(); i++ {
-		field := of.Field(i)
-		name := field.Name
-		jsonName := field.Tag.Get("json")
-		if jsonName != "" {
-			name = jsonName
-		}
-		required := field.Tag.Get("sonata")
-		for _, v := range mapValue {
-			value := v[name]
-			if value == nil && required == "required" {
-				return fmt.Errorf("field [%s] is not exist", jsonName)
-			}
+func (j *jsonBinding) bind(body io.Reader, obj any) error {
+	if !j.IsValidate {
+		if err := j.decode(json.NewDecoder(body), obj); err != nil {
+			return err
 		}
+		return validate(obj)
+	}
 
-		println(name)
+	var raw json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return err
+	}
+	if err := j.decode(json.NewDecoder(bytes.NewReader(raw)), obj); err != nil {
+		return err
+	}
+	missing, err := checkRequired(obj, raw)
+	if err != nil {
+		return err
 	}
-	b, _ := json.Marshal(mapValue)
-	_ = json.Unmarshal(b, obj)
-	return nil
+	err = validate(obj)
+	if len(missing) == 0 {
+		return err
+	}
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		return append(missing, errs...)
+	}
+	return missing
 }
 
-func checkParam(of reflect.Value, obj any, decoder *json.Decoder) error {
-	mapValue := make(map[string]any)
-	_ = decoder.Decode(&mapValue)
-	for i := 0; i < of.NumField(); i++ {
-		field := of.Type().Field(i)
-		name := field.Name
-		jsonName := field.Tag.Get("json")
-		if jsonName != "" {
-			name = jsonName
-		}
-		required := field.Tag.Get("sonata")
-		value := mapValue[name]
-		if value == nil && required == "required" {
-			return fmt.Errorf("field [%s] is not exist", jsonName)
-		}
-		println(name)
+func (j *jsonBinding) decode(decoder *json.Decoder, obj any) error {
+	if j.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
 	}
-	b, _ := json.Marshal(mapValue)
-	_ = json.Unmarshal(b, obj)
-	return nil
+	return decoder.Decode(obj)
 }
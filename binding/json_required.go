@@ -0,0 +1,149 @@
+package binding
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// fieldPath is the path of a value in Go and in JSON, e.g. Items[2].SKU
+// and items[2].sku.
+type fieldPath struct {
+	goPath   string
+	jsonPath string
+}
+
+func (p fieldPath) index(index string) fieldPath {
+	return fieldPath{goPath: p.goPath + "[" + index + "]", jsonPath: p.jsonPath + "[" + index + "]"}
+}
+
+func (p fieldPath) field(goName string, jsonName string) fieldPath {
+	return fieldPath{goPath: joinFieldPath(p.goPath, goName), jsonPath: joinFieldPath(p.jsonPath, jsonName)}
+}
+
+// checkRequired walks the type of obj along the decoded JSON body and
+// returns every field tagged sonata:"required" that is missing or null.
+func checkRequired(obj any, raw json.RawMessage) (ValidationErrors, error) {
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	var missing ValidationErrors
+	walkRequired(reflect.TypeOf(obj), data, fieldPath{}, &missing)
+	return missing.Translate(Translator()), nil
+}
+
+func walkRequired(t reflect.Type, data any, path fieldPath, missing *ValidationErrors) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if data == nil || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if object, ok := data.(map[string]any); ok {
+			walkStructRequired(t, object, path, missing)
+		}
+	case reflect.Slice, reflect.Array:
+		if array, ok := data.([]any); ok {
+			for i, item := range array {
+				walkRequired(t.Elem(), item, path.index(strconv.Itoa(i)), missing)
+			}
+		}
+	case reflect.Map:
+		if object, ok := data.(map[string]any); ok {
+			keys := make([]string, 0, len(object))
+			for key := range object {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walkRequired(t.Elem(), object[key], path.index(key), missing)
+			}
+		}
+	}
+}
+
+func walkStructRequired(t reflect.Type, object map[string]any, path fieldPath, missing *ValidationErrors) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, hasName := jsonFieldName(field)
+		if name == "-" && !hasName {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		// The fields of an embedded struct without name are promoted.
+		if field.Anonymous && !hasName && fieldType.Kind() == reflect.Struct {
+			walkStructRequired(fieldType, object, path, missing)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path.field(field.Name, name)
+		value, ok := lookupJSONKey(object, name)
+		if !ok || value == nil {
+			if isRequired(field) {
+				*missing = append(*missing, FieldError{
+					Field:    fieldPath.goPath,
+					JSONName: fieldPath.jsonPath,
+					Tag:      "required",
+				})
+			}
+			continue
+		}
+		walkRequired(field.Type, value, fieldPath, missing)
+	}
+}
+
+// jsonFieldName returns the name of field in JSON and whether it is given
+// by the json tag. The name "-" without options means the field is skipped.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return field.Name, false
+	}
+	if tag == "-" {
+		return "-", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name, false
+	}
+	return name, true
+}
+
+// lookupJSONKey finds key in object, like encoding/json preferring an exact
+// match over a case insensitive one.
+func lookupJSONKey(object map[string]any, key string) (any, bool) {
+	if value, ok := object[key]; ok {
+		return value, true
+	}
+	for k, value := range object {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, opt := range strings.Split(field.Tag.Get("sonata"), ",") {
+		if strings.TrimSpace(opt) == "required" {
+			return true
+		}
+	}
+	return false
+}
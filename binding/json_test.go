@@ -0,0 +1,107 @@
+package binding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type requiredItem struct {
+	SKU string `json:"sku,omitempty" sonata:"required"`
+	Qty int    `json:"qty"`
+}
+
+type RequiredOwner struct {
+	Owner string `json:"owner" sonata:"required"`
+}
+
+type requiredOrder struct {
+	RequiredOwner
+	ID       *int           `json:"id,omitempty" sonata:"required"`
+	Items    []requiredItem `json:"items" sonata:"required"`
+	Shipping *struct {
+		Zip string `json:"zip" sonata:"required"`
+	} `json:"shipping"`
+	Meta    map[string]requiredItem `json:"meta"`
+	Ignored string                  `json:"-" sonata:"required"`
+	NoTag   string                  `sonata:"required"`
+}
+
+func bindRequired(t *testing.T, body string, obj any) error {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	b := *JSON
+	b.IsValidate = true
+	return b.Bind(r, obj)
+}
+
+func TestJSONRequiredPaths(t *testing.T) {
+	tests := []struct {
+		body  string
+		paths []string
+	}{
+		{
+			`{"owner":"o","id":1,"items":[{"sku":"a"}],"NoTag":"x"}`,
+			nil,
+		},
+		{
+			`{}`,
+			[]string{"owner", "id", "items", "NoTag"},
+		},
+		{
+			`{"owner":null,"id":1,"items":[{"sku":"a"},{"qty":1},{"sku":null}],"notag":"x"}`,
+			[]string{"owner", "items[1].sku", "items[2].sku"},
+		},
+		{
+			`{"owner":"o","id":1,"items":[],"NoTag":"x","shipping":{},"meta":{"b":{},"a":{"sku":"s"},"c":{}}}`,
+			[]string{"shipping.zip", "meta[b].sku", "meta[c].sku"},
+		},
+	}
+	for _, tt := range tests {
+		var order requiredOrder
+		err := bindRequired(t, tt.body, &order)
+		var paths []string
+		if errs, ok := err.(ValidationErrors); ok {
+			for _, fe := range errs {
+				if fe.Tag != "required" {
+					t.Errorf("%s: tag = %q, want required", tt.body, fe.Tag)
+				}
+				paths = append(paths, fe.JSONName)
+			}
+		} else if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.body, err)
+			continue
+		}
+		if !reflect.DeepEqual(paths, tt.paths) {
+			t.Errorf("%s: missing %v, want %v", tt.body, paths, tt.paths)
+		}
+	}
+}
+
+func TestJSONRequiredFieldPath(t *testing.T) {
+	var items []requiredItem
+	err := bindRequired(t, `[{"sku":"a"},{"qty":2}]`, &items)
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("Bind = %v, want one ValidationError", err)
+	}
+	want := FieldError{Field: "[1].SKU", JSONName: "[1].sku", Tag: "required", Message: "[1].sku is a required field"}
+	if errs[0] != want {
+		t.Errorf("got %+v, want %+v", errs[0], want)
+	}
+	if len(items) != 2 || items[1].Qty != 2 {
+		t.Errorf("items = %+v, want the decoded body", items)
+	}
+}
+
+func TestJSONRequiredDecodeErrors(t *testing.T) {
+	for _, body := range []string{`{bad`, `{"id":"x"}`, ``} {
+		var order requiredOrder
+		err := bindRequired(t, body, &order)
+		if _, ok := err.(ValidationErrors); ok || err == nil {
+			t.Errorf("%q: Bind = %v, want a decode error", body, err)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package binding
+
+import "net/http"
+
+type queryBinding struct {
+}
+
+func (q *queryBinding) Name() string {
+	return "query"
+}
+
+// Bind maps the URL query of r to the form tags of obj.
+func (q *queryBinding) Bind(r *http.Request, obj any) error {
+	if err := mapForm(obj, r.URL.Query()); err != nil {
+		return err
+	}
+	return validate(obj)
+}
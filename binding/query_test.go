@@ -0,0 +1,70 @@
+package binding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQueryBinding(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/?page=2&tag=a&tag=b", nil)
+	var got struct {
+		Page int      `form:"page" validate:"min=1"`
+		Size int      `form:"size,default=20"`
+		Tags []string `form:"tag"`
+	}
+	if err := Query.Bind(r, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Page != 2 || got.Size != 20 || len(got.Tags) != 2 {
+		t.Errorf("got %+v", got)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?page=0", nil)
+	if _, ok := Query.Bind(r, &got).(ValidationErrors); !ok {
+		t.Errorf("Query.Bind with page=0 should fail validation")
+	}
+}
+
+func TestHeaderBinding(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-Id", "abc")
+	r.Header.Add("Accept-Language", "en")
+	r.Header.Add("Accept-Language", "fr")
+	r.Header.Set("X-Rate", "10")
+
+	type headers struct {
+		RequestID string   `header:"x-request-id" validate:"required"`
+		Languages []string `header:"Accept-Language"`
+		Rate      int      `header:"X-RATE"`
+	}
+	var got headers
+	if err := Header.Bind(r, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.RequestID != "abc" || len(got.Languages) != 2 || got.Rate != 10 {
+		t.Errorf("got %+v", got)
+	}
+
+	r.Header.Del("X-Request-Id")
+	if _, ok := Header.Bind(r, &headers{}).(ValidationErrors); !ok {
+		t.Errorf("Header.Bind without X-Request-Id should fail validation")
+	}
+}
+
+func TestUriBinding(t *testing.T) {
+	var got struct {
+		ID   int    `uri:"id" validate:"min=1"`
+		Name string `uri:"name"`
+	}
+	if err := Uri.BindUri(map[string][]string{"id": {"5"}, "name": {"bob"}}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 5 || got.Name != "bob" {
+		t.Errorf("got %+v", got)
+	}
+
+	if err := Uri.BindUri(map[string][]string{"id": {"x"}}, &got); err == nil {
+		t.Errorf("BindUri with id=x should fail")
+	}
+}
@@ -0,0 +1,77 @@
+package binding
+
+import (
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+)
+
+var (
+	translatorMu sync.RWMutex
+	universal    = ut.New(en.New(), en.New())
+)
+
+// defaultMessages are the English messages of the common validation tags.
+// {0} is replaced by the JSON path of the field and {1} by the tag
+// parameter.
+var defaultMessages = map[string]string{
+	"required": "{0} is a required field",
+	"len":      "{0} must have a length of {1}",
+	"min":      "{0} must be at least {1}",
+	"max":      "{0} must be at most {1}",
+	"eq":       "{0} must be equal to {1}",
+	"ne":       "{0} must not be equal to {1}",
+	"gt":       "{0} must be greater than {1}",
+	"gte":      "{0} must be greater than or equal to {1}",
+	"lt":       "{0} must be less than {1}",
+	"lte":      "{0} must be less than or equal to {1}",
+	"oneof":    "{0} must be one of [{1}]",
+	"email":    "{0} must be a valid email address",
+	"url":      "{0} must be a valid URL",
+	"uuid":     "{0} must be a valid UUID",
+	"alpha":    "{0} can only contain alphabetic characters",
+	"alphanum": "{0} can only contain alphanumeric characters",
+	"numeric":  "{0} must be a valid numeric value",
+}
+
+func init() {
+	if err := RegisterTranslations(en.New(), defaultMessages); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterTranslations adds the messages of locale for the validation
+// tags, e.g. RegisterTranslations(zh.New(), map[string]string{"required":
+// "{0}为必填字段"}) with github.com/go-playground/locales/zh. In the
+// messages, {0} is replaced by the JSON path of the field and {1} by the tag
+// parameter. The messages of a tag already registered are replaced.
+func RegisterTranslations(locale locales.Translator, messages map[string]string) error {
+	translatorMu.Lock()
+	defer translatorMu.Unlock()
+
+	trans, found := universal.GetTranslator(locale.Locale())
+	if !found {
+		if err := universal.AddTranslator(locale, false); err != nil {
+			return err
+		}
+		trans, _ = universal.GetTranslator(locale.Locale())
+	}
+
+	for tag, message := range messages {
+		if err := trans.Add(tag, message, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Translator returns the translator of the first of locales that is
+// registered, or the English one.
+func Translator(locales ...string) ut.Translator {
+	translatorMu.RLock()
+	defer translatorMu.RUnlock()
+	trans, _ := universal.FindTranslator(locales...)
+	return trans
+}
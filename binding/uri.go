@@ -0,0 +1,16 @@
+package binding
+
+type uriBinding struct {
+}
+
+func (u *uriBinding) Name() string {
+	return "uri"
+}
+
+// BindUri maps the path parameters of the route to the uri tags of obj.
+func (u *uriBinding) BindUri(params map[string][]string, obj any) error {
+	if err := mapFormByTag(obj, params, "uri"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
@@ -0,0 +1,97 @@
+package binding
+
+import (
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator"
+)
+
+// FieldError describes a field of the bound object that failed validation.
+type FieldError struct {
+	// Field is the path of the field in Go, e.g. "Items[2].SKU".
+	Field string `json:"field"`
+	// JSONName is the path of the field in JSON, e.g. "items[2].sku".
+	JSONName string `json:"json"`
+	// Tag is the validation tag that failed, e.g. "required" or "min".
+	Tag string `json:"tag"`
+	// Param is the parameter of the tag, e.g. "3" for min=3.
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is returned by the bindings when the bound object fails
+// validation, with the messages in the default language.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fe := range e {
+		messages = append(messages, fe.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Translate returns a copy of e with the messages of trans, see Translator.
+func (e ValidationErrors) Translate(trans ut.Translator) ValidationErrors {
+	translated := make(ValidationErrors, len(e))
+	for i, fe := range e {
+		fe.Message = translateFieldError(trans, fe)
+		translated[i] = fe
+	}
+	return translated
+}
+
+func (e ValidationErrors) withPrefix(goPrefix string, jsonPrefix string) ValidationErrors {
+	for i := range e {
+		e[i].Field = joinFieldPath(goPrefix, e[i].Field)
+		e[i].JSONName = joinFieldPath(jsonPrefix, e[i].JSONName)
+	}
+	return e
+}
+
+// fromValidatorErrors converts the errors of go-playground/validator. Their
+// namespaces start with the name of the validated struct, which is removed.
+func fromValidatorErrors(errs validator.ValidationErrors) ValidationErrors {
+	result := make(ValidationErrors, 0, len(errs))
+	trans := Translator()
+	for _, err := range errs {
+		fe := FieldError{
+			Field:    trimRootNamespace(err.StructNamespace()),
+			JSONName: trimRootNamespace(err.Namespace()),
+			Tag:      err.Tag(),
+			Param:    err.Param(),
+		}
+		fe.Message = translateFieldError(trans, fe)
+		result = append(result, fe)
+	}
+	return result
+}
+
+// translateFieldError returns the message of trans for the tag of fe, or
+// else the English one. The messages are read under translatorMu since
+// RegisterTranslations can add some at any time.
+func translateFieldError(trans ut.Translator, fe FieldError) string {
+	translatorMu.RLock()
+	defer translatorMu.RUnlock()
+	for _, t := range []ut.Translator{trans, universal.GetFallback()} {
+		if message, err := t.T(fe.Tag, fe.JSONName, fe.Param); err == nil {
+			return message
+		}
+	}
+	return "Field validation for '" + fe.JSONName + "' failed on the '" + fe.Tag + "' tag"
+}
+
+func trimRootNamespace(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func joinFieldPath(prefix string, path string) string {
+	if prefix == "" || strings.HasPrefix(path, "[") {
+		return prefix + path
+	}
+	return prefix + "." + path
+}
@@ -0,0 +1,60 @@
+package binding
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-playground/locales/de"
+)
+
+type validatedItem struct {
+	SKU string `json:"sku" validate:"required"`
+}
+
+type validatedOrder struct {
+	Name  string          `json:"name" validate:"min=3"`
+	Items []validatedItem `json:"items" validate:"dive"`
+}
+
+func TestValidationErrorsFields(t *testing.T) {
+	err := validate(&validatedOrder{Name: "ab", Items: []validatedItem{{}}})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("validate = %v, want ValidationErrors", err)
+	}
+	want := ValidationErrors{
+		{Field: "Name", JSONName: "name", Tag: "min", Param: "3", Message: "name must be at least 3"},
+		{Field: "Items[0].SKU", JSONName: "items[0].sku", Tag: "required", Message: "items[0].sku is a required field"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("got %+v, want %+v", errs, want)
+	}
+}
+
+func TestTranslateWhileRegistering(t *testing.T) {
+	errs := ValidationErrors{{JSONName: "name", Tag: "required"}}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			if err := RegisterTranslations(de.New(), map[string]string{"required": "{0} ist ein Pflichtfeld"}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			errs.Translate(Translator("de"))
+		}
+	}()
+	wg.Wait()
+
+	if got := errs.Translate(Translator("de"))[0].Message; got != "name ist ein Pflichtfeld" {
+		t.Errorf("Message = %q, want the German one", got)
+	}
+}
@@ -1,8 +1,10 @@
 package binding
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -56,15 +58,24 @@ func (d *defaultValidator) ValidateStruct(obj any) error {
 	case reflect.Slice, reflect.Array:
 		count := of.Len()
 		sliceValidationError := make(SliceValidationError, 0)
+		var validationErrors ValidationErrors
 		for i := 0; i < count; i++ {
-			if err := d.validateStruct(of.Index(i).Interface()); err != nil {
+			err := d.validateStruct(of.Index(i).Interface())
+			var errs ValidationErrors
+			if errors.As(err, &errs) {
+				prefix := "[" + strconv.Itoa(i) + "]"
+				validationErrors = append(validationErrors, errs.withPrefix(prefix, prefix)...)
+			} else if err != nil {
 				sliceValidationError = append(sliceValidationError, err)
 			}
 		}
-		if len(sliceValidationError) == 0 {
-			return nil
+		if len(sliceValidationError) > 0 {
+			return sliceValidationError
 		}
-		return sliceValidationError
+		if len(validationErrors) > 0 {
+			return validationErrors.Translate(Translator())
+		}
+		return nil
 	}
 	return nil
 }
@@ -77,12 +88,23 @@ func (d *defaultValidator) Engine() any {
 func (d *defaultValidator) lazyInit() {
 	d.one.Do(func() {
 		d.validate = validator.New()
+		d.validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			if name, _ := jsonFieldName(field); name != "-" {
+				return name
+			}
+			return ""
+		})
 	})
 }
 
 func (d *defaultValidator) validateStruct(obj any) error {
 	d.lazyInit()
-	return d.validate.Struct(obj)
+	err := d.validate.Struct(obj)
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return fromValidatorErrors(errs)
+	}
+	return err
 }
 
 func validate(obj any) error {
@@ -1,7 +1,9 @@
 package binding
 
 import (
+	"bytes"
 	"encoding/xml"
+	"io"
 	"net/http"
 )
 
@@ -16,7 +18,15 @@ func (x *xmlBinding) Bind(r *http.Request, obj any) error {
 	if r.Body == nil {
 		return nil
 	}
-	decoder := xml.NewDecoder(r.Body)
+	return x.bind(r.Body, obj)
+}
+
+func (x *xmlBinding) BindBody(body []byte, obj any) error {
+	return x.bind(bytes.NewReader(body), obj)
+}
+
+func (x *xmlBinding) bind(body io.Reader, obj any) error {
+	decoder := xml.NewDecoder(body)
 	err := decoder.Decode(obj)
 	if err != nil {
 		return err
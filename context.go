@@ -1,33 +1,374 @@
 package sonata
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"html/template"
 	"io"
 	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mneumi/sonata/binding"
 	"github.com/mneumi/sonata/render"
 )
 
-const defaultMultipartMemory = 32 << 20 // 32M
+const (
+	defaultMultipartMemory = 32 << 20 // 32M
+	defaultMaxBodyBytes    = 10 << 20 // 10M
+)
+
+// BodyBytesKey is the key of the request body cached by GetRawData.
+const BodyBytesKey = "_sonata/bodybyteskey"
+
+const abortIndex = math.MaxInt32
+
+const (
+	mimeProblemJSON = "application/problem+json"
+	mimeProblemXML  = "application/problem+xml"
+)
+
+var ErrCopiedContextWrite = errors.New("sonata: can not write the response of a copied Context")
+
+var ErrBodyTooLarge = errors.New("sonata: request body too large")
+
+type copiedResponseWriter struct {
+	header http.Header
+}
+
+func (w *copiedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *copiedResponseWriter) Write([]byte) (int, error) {
+	return 0, ErrCopiedContextWrite
+}
+
+func (w *copiedResponseWriter) WriteHeader(int) {}
+
+// contextSelfKey lets WithContext detect contexts derived from the Context.
+type contextSelfKey struct{}
+
+// detachedContext keeps the values of its parent but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key any) any {
+	return d.parent.Value(key)
+}
 
 type Context struct {
-	W                     http.ResponseWriter
+	W                     ResponseWriter
+	writermem             responseWriter
 	R                     *http.Request
 	engine                *Engine
+	params                Params
+	handlers              HandlersChain
+	index                 int
+	mu                    sync.RWMutex
+	Keys                  map[string]any
+	Errors                errorMsgs
+	errorsHandled         bool
 	queryCache            url.Values
 	postFormCache         url.Values
 	DisallowUnknownFields bool
 	IsValidate            bool
-	StatusCode            int
+}
+
+var _ context.Context = (*Context)(nil)
+
+// reset clears the state left by the previous request when the Context is
+// taken from the pool.
+func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
+	c.writermem.reset(w)
+	c.W = &c.writermem
+	c.R = r
+	c.params = c.params[:0]
+	c.handlers = nil
+	c.index = -1
+	c.Keys = nil
+	c.Errors = c.Errors[:0]
+	c.errorsHandled = false
+	c.queryCache = nil
+	c.postFormCache = nil
+	c.DisallowUnknownFields = false
+	c.IsValidate = false
+}
+
+// Copy returns a snapshot of the Context that can be used after the handler
+// returns, e.g. in a goroutine. The copy is read-only: it has no handler
+// chain, writing its response fails with ErrCopiedContextWrite, and its
+// request is detached from the cancellation of the original request.
+func (c *Context) Copy() *Context {
+	cp := &Context{
+		writermem: responseWriter{
+			ResponseWriter: &copiedResponseWriter{header: c.W.Header().Clone()},
+			size:           c.W.Size(),
+			status:         c.W.Status(),
+		},
+		engine:                c.engine,
+		index:                 abortIndex,
+		DisallowUnknownFields: c.DisallowUnknownFields,
+		IsValidate:            c.IsValidate,
+	}
+	cp.W = &cp.writermem
+	if c.R != nil {
+		cp.R = c.R.Clone(detachedContext{parent: c.R.Context()})
+	}
+
+	cp.params = make(Params, len(c.params))
+	copy(cp.params, c.params)
+	cp.Errors = append(errorMsgs(nil), c.Errors...)
+
+	c.mu.RLock()
+	if c.Keys != nil {
+		cp.Keys = make(map[string]any, len(c.Keys))
+		for key, value := range c.Keys {
+			cp.Keys[key] = value
+		}
+	}
+	c.mu.RUnlock()
+
+	return cp
+}
+
+// WithContext replaces the context of the request, so the values and
+// deadlines attached by a middleware are honored by the handlers that follow
+// and by everything they pass the Context to:
+//
+//	ctx, cancel := context.WithTimeout(c.R.Context(), time.Second)
+//	defer cancel()
+//	c.WithContext(ctx)
+//	c.Next()
+//
+// Since the Context delegates to the request context, ctx must be derived
+// from c.R.Context() and not from the Context itself.
+func (c *Context) WithContext(ctx context.Context) {
+	if ctx.Value(contextSelfKey{}) == c {
+		panic("err: WithContext requires a context derived from c.R.Context(), not from c")
+	}
+	if c.R != nil {
+		c.R = c.R.WithContext(ctx)
+	}
+}
+
+// Deadline, Done, Err and Value make Context a context.Context bound to the
+// request. The Context is reused once the handler returns, so goroutines
+// outliving the request must use Copy instead.
+func (c *Context) Deadline() (time.Time, bool) {
+	if c.R == nil {
+		return time.Time{}, false
+	}
+	return c.R.Context().Deadline()
+}
+
+func (c *Context) Done() <-chan struct{} {
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Done()
+}
+
+func (c *Context) Err() error {
+	if c.R == nil {
+		return nil
+	}
+	return c.R.Context().Err()
+}
+
+// Value returns the value of key in the request context, or else the value
+// stored under key with Set when key is a string.
+func (c *Context) Value(key any) any {
+	if key == (contextSelfKey{}) {
+		return c
+	}
+	if c.R != nil {
+		if value := c.R.Context().Value(key); value != nil {
+			return value
+		}
+	}
+	if name, ok := key.(string); ok {
+		value, _ := c.Get(name)
+		return value
+	}
+	return nil
+}
+
+// Next runs the pending handlers of the chain. It is meant to be called by
+// middlewares, which can act on the response after Next returns.
+func (c *Context) Next() {
+	c.index++
+	for c.index < len(c.handlers) {
+		c.handlers[c.index](c)
+		if c.index == len(c.handlers)-1 || c.IsAborted() {
+			// Handle the errors once the chain ends or is aborted, before
+			// the middlewares resume, so they see the final response.
+			c.engine.handleErrors(c)
+		}
+		c.index++
+	}
+}
+
+// Error collects err to be answered by Engine.ErrorHandler once the handler
+// returns. Errors that are not an *Error get the ErrorTypePrivate type.
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("err: Context.Error called with a nil error")
+	}
+
+	parsedError, ok := err.(*Error)
+	if !ok {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
+}
+
+// Abort prevents the pending handlers of the chain from being called. The
+// handlers that already called Next still resume after it returns.
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+func (c *Context) AbortWithStatus(status int) {
+	c.Abort()
+	c.W.WriteHeader(status)
+	c.W.WriteHeaderNow()
+}
+
+func (c *Context) AbortWithStatusJSON(status int, data any) error {
+	c.Abort()
+	return c.JSON(status, data)
+}
+
+func (c *Context) Param(name string) string {
+	return c.params.ByName(name)
+}
+
+func (c *Context) Params() Params {
+	return c.params
+}
+
+// Set stores value under key for the rest of the request, e.g. to pass the
+// authenticated user from a middleware to the handler.
+func (c *Context) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]any)
+	}
+	c.Keys[key] = value
+}
+
+func (c *Context) Get(key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.Keys[key]
+	return value, ok
+}
+
+func (c *Context) MustGet(key string) any {
+	if value, ok := c.Get(key); ok {
+		return value
+	}
+	panic("err: key \"" + key + "\" does not exist")
+}
+
+// Value returns the value stored under key if it has the type T.
+func Value[T any](c *Context, key string) (T, bool) {
+	value, ok := c.Get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := value.(T)
+	return t, ok
+}
+
+func getValue[T any](c *Context, key string) T {
+	value, _ := Value[T](c, key)
+	return value
+}
+
+func (c *Context) GetString(key string) string {
+	return getValue[string](c, key)
+}
+
+func (c *Context) GetBool(key string) bool {
+	return getValue[bool](c, key)
+}
+
+func (c *Context) GetInt(key string) int {
+	return getValue[int](c, key)
+}
+
+func (c *Context) GetInt64(key string) int64 {
+	return getValue[int64](c, key)
+}
+
+func (c *Context) GetUint(key string) uint {
+	return getValue[uint](c, key)
+}
+
+func (c *Context) GetUint64(key string) uint64 {
+	return getValue[uint64](c, key)
+}
+
+func (c *Context) GetFloat64(key string) float64 {
+	return getValue[float64](c, key)
+}
+
+func (c *Context) GetTime(key string) time.Time {
+	return getValue[time.Time](c, key)
+}
+
+func (c *Context) GetDuration(key string) time.Duration {
+	return getValue[time.Duration](c, key)
+}
+
+func (c *Context) GetStringSlice(key string) []string {
+	return getValue[[]string](c, key)
+}
+
+func (c *Context) GetStringMap(key string) map[string]any {
+	return getValue[map[string]any](c, key)
+}
+
+func (c *Context) GetStringMapString(key string) map[string]string {
+	return getValue[map[string]string](c, key)
 }
 
 func (c *Context) initQueryCache() {
+	if c.queryCache != nil {
+		return
+	}
 	if c.R != nil {
 		c.queryCache = c.R.URL.Query()
 	} else {
@@ -36,6 +377,9 @@ func (c *Context) initQueryCache() {
 }
 
 func (c *Context) initPostFormCache() {
+	if c.postFormCache != nil {
+		return
+	}
 	if c.R != nil {
 		if err := c.R.ParseMultipartForm(defaultMultipartMemory); err != nil {
 			if !errors.Is(err, http.ErrNotMultipart) {
@@ -43,7 +387,8 @@ func (c *Context) initPostFormCache() {
 			}
 		}
 		c.postFormCache = c.R.PostForm
-	} else {
+	}
+	if c.postFormCache == nil {
 		c.postFormCache = url.Values{}
 	}
 }
@@ -122,11 +467,41 @@ func (c *Context) MultpartForm() (*multipart.Form, error) {
 	return c.R.MultipartForm, err
 }
 
+// ContentType returns the media type of the request body, without its
+// parameters.
+func (c *Context) ContentType() string {
+	contentType, _, _ := strings.Cut(c.R.Header.Get("Content-Type"), ";")
+	return strings.TrimSpace(contentType)
+}
+
+// Bind binds the request body with the binding selected by binding.Default
+// from the request method and Content-Type. Like MustBindWith, it sets the
+// status to 400 Bad Request on error, or to 415 Unsupported Media Type if
+// no binding supports the Content-Type.
+func (c *Context) Bind(obj any) error {
+	bind := binding.Default(c.R.Method, c.ContentType())
+	if bind == nil {
+		c.W.WriteHeader(http.StatusUnsupportedMediaType)
+		c.Error(binding.ErrUnsupportedMediaType).SetType(ErrorTypeBind)
+		return binding.ErrUnsupportedMediaType
+	}
+	return c.MustBindWith(obj, bind)
+}
+
+// ShouldBind is like Bind but leaves the response untouched on error.
+func (c *Context) ShouldBind(obj any) error {
+	bind := binding.Default(c.R.Method, c.ContentType())
+	if bind == nil {
+		return binding.ErrUnsupportedMediaType
+	}
+	return c.ShouldBindWith(obj, bind)
+}
+
 func (c *Context) BindJSON(obj any) error {
-	jb := binding.JSON
+	jb := *binding.JSON
 	jb.DisallowUnknownFields = true
 	jb.IsValidate = true
-	return c.MustBindWith(obj, jb)
+	return c.MustBindWith(obj, &jb)
 }
 
 func (c *Context) BindXML(obj any) error {
@@ -134,17 +509,123 @@ func (c *Context) BindXML(obj any) error {
 }
 
 func (c *Context) MustBindWith(obj any, bind binding.Binding) error {
-	if err := c.ShouldBind(obj, bind); err != nil {
+	if err := c.ShouldBindWith(obj, bind); err != nil {
 		c.W.WriteHeader(http.StatusBadRequest)
+		c.Error(err).SetType(ErrorTypeBind)
 		return err
 	}
 	return nil
 }
 
-func (c *Context) ShouldBind(obj any, bind binding.Binding) error {
+func (c *Context) ShouldBindWith(obj any, bind binding.Binding) error {
+	c.restoreBody()
+	defer c.restoreBody()
 	return bind.Bind(c.R, obj)
 }
 
+// ShouldBindBodyWith binds the request body like ShouldBindWith, but reads
+// it with GetRawData first, so the body can be bound again afterwards,
+// e.g. as JSON and then as XML.
+func (c *Context) ShouldBindBodyWith(obj any, bind binding.BindingBody) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	return bind.BindBody(body, obj)
+}
+
+// GetRawData returns the request body. It is read once, up to
+// Engine.MaxBodyBytes, and cached under BodyBytesKey; c.R.Body is then
+// replaced by a reader of the cached bytes, so the body can still be read
+// by the following handlers.
+func (c *Context) GetRawData() ([]byte, error) {
+	if body, ok := c.cachedBody(); ok {
+		c.restoreBody()
+		return body, nil
+	}
+	if c.R.Body == nil {
+		return nil, errors.New("sonata: request has no body")
+	}
+
+	var r io.Reader = c.R.Body
+	limit := c.engine.MaxBodyBytes
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+	body, err := io.ReadAll(r)
+	if err == nil && limit > 0 && int64(len(body)) > limit {
+		err = ErrBodyTooLarge
+	}
+	if err != nil {
+		// Put back what was read for the handlers that read the body
+		// without limit.
+		c.R.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), c.R.Body), c.R.Body}
+		return nil, err
+	}
+	c.R.Body.Close()
+
+	c.Set(BodyBytesKey, body)
+	c.restoreBody()
+	return body, nil
+}
+
+func (c *Context) cachedBody() ([]byte, bool) {
+	value, _ := c.Get(BodyBytesKey)
+	body, ok := value.([]byte)
+	return body, ok
+}
+
+// restoreBody rewinds c.R.Body to the start of the cached body, if any.
+func (c *Context) restoreBody() {
+	if body, ok := c.cachedBody(); ok {
+		c.R.Body = io.NopCloser(bytes.NewReader(body))
+	}
+}
+
+// ShouldBindQuery binds the URL query to the form tags of obj.
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBindWith(obj, binding.Query)
+}
+
+// ShouldBindHeader binds the request headers to the header tags of obj.
+func (c *Context) ShouldBindHeader(obj any) error {
+	return c.ShouldBindWith(obj, binding.Header)
+}
+
+// ShouldBindUri binds the path parameters to the uri tags of obj.
+func (c *Context) ShouldBindUri(obj any) error {
+	params := make(map[string][]string, len(c.params))
+	for _, param := range c.params {
+		params[param.Key] = []string{param.Value}
+	}
+	return binding.Uri.BindUri(params, obj)
+}
+
+// ValidationErrors returns the validation errors of err, with the messages
+// in the language preferred by the Accept-Language header of the request
+// among those registered with binding.RegisterTranslations.
+func (c *Context) ValidationErrors(err error) (binding.ValidationErrors, bool) {
+	var errs binding.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+	return errs.Translate(binding.Translator(c.acceptedLanguages()...)), true
+}
+
+// AbortWithValidationErrors aborts with status, usually 400 Bad Request or
+// 422 Unprocessable Entity, and a JSON body listing the validation errors
+// of err under "errors". The message of any other error is written under
+// "error".
+func (c *Context) AbortWithValidationErrors(status int, err error) error {
+	if errs, ok := c.ValidationErrors(err); ok {
+		return c.AbortWithStatusJSON(status, map[string]any{"errors": errs})
+	}
+	return c.AbortWithStatusJSON(status, map[string]any{"error": err.Error()})
+}
+
 func (c *Context) HTML(status int, html string) error {
 	return c.Render(status, &render.HTML{
 		Data: html,
@@ -209,9 +690,30 @@ func (c *Context) String(status int, format string, values ...any) error {
 	})
 }
 
+// Problem writes an RFC 9457 problem details document, as XML if the
+// client prefers it over JSON. The status defaults to 500 Internal Server
+// Error and the title to the status text.
+func (c *Context) Problem(problem render.Problem) error {
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	format := c.NegotiateFormat(mimeProblemJSON, mimeProblemXML, binding.MIMEJSON, binding.MIMEXML, binding.MIMEXML2)
+	if strings.HasSuffix(format, "xml") {
+		return c.Render(problem.Status, &render.ProblemXML{Data: &problem})
+	}
+	return c.Render(problem.Status, &render.ProblemJSON{Data: &problem})
+}
+
 func (c *Context) Render(status int, r render.Render) error {
 	r.WriteContentType(c.W)
 	r.WriteHeader(status, c.W)
-	c.StatusCode = status
-	return r.Render(c.W)
+	if err := r.Render(c.W); err != nil {
+		c.Error(err).SetType(ErrorTypeRender)
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,462 @@
+package sonata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/locales/fr"
+	"github.com/mneumi/sonata/binding"
+)
+
+func TestShouldBindUri(t *testing.T) {
+	e := New()
+	var got struct {
+		ID   int    `uri:"id" validate:"min=1"`
+		Name string `uri:"name"`
+	}
+	var bindErr error
+	e.Group("").Get("/users/:id/:name", func(ctx *Context) {
+		bindErr = ctx.ShouldBindUri(&got)
+	})
+
+	performRequest(e, http.MethodGet, "/users/5/bob")
+	if bindErr != nil || got.ID != 5 || got.Name != "bob" {
+		t.Errorf("ShouldBindUri = %v, %+v, want ID 5 and Name bob", bindErr, got)
+	}
+
+	performRequest(e, http.MethodGet, "/users/0/bob")
+	if bindErr == nil {
+		t.Errorf("ShouldBindUri with id 0 should fail validation")
+	}
+}
+
+func traceMiddleware(trace *[]string, name string) MiddlewareFunc {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			*trace = append(*trace, name+" before")
+			next(ctx)
+			*trace = append(*trace, name+" after")
+		}
+	}
+}
+
+func TestHandlerChain(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(e *Engine, trace *[]string)
+		trace  []string
+		status int
+	}{
+		{
+			name: "middlewares resume after next in reverse order",
+			setup: func(e *Engine, trace *[]string) {
+				e.Use(traceMiddleware(trace, "global"))
+				g := e.Group("").Group("", traceMiddleware(trace, "group"))
+				g.Get("/", func(ctx *Context) {
+					*trace = append(*trace, "handler")
+				}, traceMiddleware(trace, "route"))
+			},
+			trace:  []string{"global before", "group before", "route before", "handler", "route after", "group after", "global after"},
+			status: http.StatusOK,
+		},
+		{
+			name: "handlers driving the chain with Next",
+			setup: func(e *Engine, trace *[]string) {
+				g := e.Group("")
+				g.UseHandlers(func(ctx *Context) {
+					*trace = append(*trace, "first before")
+					ctx.Next()
+					*trace = append(*trace, "first after")
+				}, func(ctx *Context) {
+					// Without Next, the chain continues after it returns.
+					*trace = append(*trace, "second")
+				})
+				g.Get("/", func(ctx *Context) {
+					*trace = append(*trace, "handler")
+				})
+			},
+			trace:  []string{"first before", "second", "handler", "first after"},
+			status: http.StatusOK,
+		},
+		{
+			name: "Abort stops the following handlers",
+			setup: func(e *Engine, trace *[]string) {
+				g := e.Group("").Group("", traceMiddleware(trace, "outer"))
+				g.UseHandlers(func(ctx *Context) {
+					*trace = append(*trace, "abort")
+					ctx.AbortWithStatus(http.StatusUnauthorized)
+				}, func(ctx *Context) {
+					*trace = append(*trace, "skipped")
+				})
+				g.Get("/", func(ctx *Context) {
+					*trace = append(*trace, "handler")
+				})
+			},
+			trace:  []string{"outer before", "abort", "outer after"},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "a middleware not calling next aborts the chain",
+			setup: func(e *Engine, trace *[]string) {
+				g := e.Group("").Group("", func(next HandleFunc) HandleFunc {
+					return func(ctx *Context) {
+						*trace = append(*trace, "deny")
+						ctx.AbortWithStatusJSON(http.StatusForbidden, map[string]string{"error": "denied"})
+					}
+				})
+				g.Get("/", func(ctx *Context) {
+					*trace = append(*trace, "handler")
+				})
+			},
+			trace:  []string{"deny"},
+			status: http.StatusForbidden,
+		},
+		{
+			name: "Use only applies to the routes registered after it",
+			setup: func(e *Engine, trace *[]string) {
+				g := e.Group("")
+				g.Get("/", func(ctx *Context) {
+					*trace = append(*trace, "handler")
+				})
+				g.Use(traceMiddleware(trace, "late"))
+				e.Use(traceMiddleware(trace, "late global"))
+			},
+			trace:  []string{"handler"},
+			status: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		e := New()
+		var trace []string
+		tt.setup(e, &trace)
+		w := performRequest(e, http.MethodGet, "/")
+		if !reflect.DeepEqual(trace, tt.trace) || w.Code != tt.status {
+			t.Errorf("%s: trace %v, status %d, want %v, %d", tt.name, trace, w.Code, tt.trace, tt.status)
+		}
+	}
+}
+
+func TestAbortWithStatusJSON(t *testing.T) {
+	e := New()
+	e.Group("").Get("/", func(ctx *Context) {
+		ctx.AbortWithStatusJSON(http.StatusTeapot, map[string]string{"error": "teapot"})
+		if !ctx.IsAborted() {
+			t.Errorf("AbortWithStatusJSON did not abort")
+		}
+	})
+	w := performRequest(e, http.MethodGet, "/")
+	if w.Code != http.StatusTeapot || w.Body.String() != `{"error":"teapot"}` {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+}
+
+type bodyTarget struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestShouldBindBodyWith(t *testing.T) {
+	e := New()
+	var afterBody string
+	e.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			body, _ := io.ReadAll(ctx.R.Body)
+			afterBody = string(body)
+		}
+	})
+
+	var jsonErr, xmlErr, rawErr error
+	var fromJSON, fromXML bodyTarget
+	var raw []byte
+	e.Group("").Post("/", func(ctx *Context) {
+		jsonErr = ctx.ShouldBindBodyWith(&fromJSON, binding.JSON)
+		xmlErr = ctx.ShouldBindBodyWith(&fromXML, binding.XML)
+		raw, rawErr = ctx.GetRawData()
+		cached, _ := ctx.Get(BodyBytesKey)
+		if !bytes.Equal(cached.([]byte), raw) {
+			t.Errorf("BodyBytesKey = %q, want %q", cached, raw)
+		}
+	})
+
+	body := `<bodyTarget><name>xml</name></bodyTarget>`
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if jsonErr == nil || xmlErr != nil || fromXML.Name != "xml" {
+		t.Errorf("JSON then XML: %v, %v, %+v", jsonErr, xmlErr, fromXML)
+	}
+	if rawErr != nil || string(raw) != body {
+		t.Errorf("GetRawData = %q, %v", raw, rawErr)
+	}
+	if afterBody != body {
+		t.Errorf("middleware read %q after the handler, want the body", afterBody)
+	}
+}
+
+func TestGetRawDataBeforeBind(t *testing.T) {
+	e := New()
+	var raw []byte
+	e.UseHandlers(func(ctx *Context) {
+		raw, _ = ctx.GetRawData()
+		ctx.Next()
+	})
+	var got bodyTarget
+	var bindErr error
+	e.Group("").Post("/", func(ctx *Context) {
+		bindErr = ctx.ShouldBindWith(&got, binding.JSON)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"json"}`)))
+	if string(raw) != `{"name":"json"}` || bindErr != nil || got.Name != "json" {
+		t.Errorf("raw %q, bind %v, %+v", raw, bindErr, got)
+	}
+}
+
+func TestGetRawDataTooLarge(t *testing.T) {
+	e := New()
+	e.MaxBodyBytes = 8
+	var rawErr, bindErr error
+	var rest []byte
+	e.Group("").Post("/", func(ctx *Context) {
+		_, rawErr = ctx.GetRawData()
+		var got bodyTarget
+		bindErr = ctx.ShouldBindBodyWith(&got, binding.JSON)
+		rest, _ = io.ReadAll(ctx.R.Body)
+	})
+
+	body := `{"name":"too large"}`
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if !errors.Is(rawErr, ErrBodyTooLarge) || !errors.Is(bindErr, ErrBodyTooLarge) {
+		t.Errorf("GetRawData = %v, ShouldBindBodyWith = %v, want ErrBodyTooLarge", rawErr, bindErr)
+	}
+	if string(rest) != body {
+		t.Errorf("body after the overflow = %q, want %q", rest, body)
+	}
+}
+
+func TestContextReset(t *testing.T) {
+	e := New()
+	c := e.allocateContext().(*Context)
+	c.reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/?a=1", strings.NewReader("b=2")))
+	c.params = append(c.params, Param{Key: "id", Value: "1"})
+	c.handlers = HandlersChain{func(*Context) {}}
+	c.index = 0
+	c.Set("key", "value")
+	c.Error(errors.New("failed"))
+	c.errorsHandled = true
+	c.R.Header.Set("Content-Type", binding.MIMEPOSTForm)
+	c.GetQuery("a")
+	c.GetPostForm("b")
+	c.DisallowUnknownFields = true
+	c.IsValidate = true
+	c.String(http.StatusTeapot, "first")
+
+	c.reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(c.Params()) != 0 || c.handlers != nil || c.index != -1 {
+		t.Errorf("chain state not cleared: %v, %v, %d", c.Params(), c.handlers, c.index)
+	}
+	if _, ok := c.Get("key"); ok || len(c.Errors) != 0 || c.errorsHandled {
+		t.Errorf("Keys or Errors not cleared: %v, %v, %v", c.Keys, c.Errors, c.errorsHandled)
+	}
+	if c.queryCache != nil || c.postFormCache != nil {
+		t.Errorf("caches not cleared: %v, %v", c.queryCache, c.postFormCache)
+	}
+	if c.DisallowUnknownFields || c.IsValidate {
+		t.Error("binding options not cleared")
+	}
+	if c.W.Written() || c.W.Status() != http.StatusOK || c.W.Size() != noWritten {
+		t.Errorf("writer not cleared: written %v, status %d, size %d", c.W.Written(), c.W.Status(), c.W.Size())
+	}
+}
+
+func TestContextCopy(t *testing.T) {
+	e := New()
+	copied := make(chan *Context, 1)
+	e.Group("").Get("/users/:id", func(ctx *Context) {
+		ctx.Set("key", ctx.Param("id"))
+		ctx.W.Header().Set("X-Test", "1")
+		copied <- ctx.Copy()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil).WithContext(ctx)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	cancel()
+	cp := <-copied
+
+	// The pooled Context is reused by the next request.
+	performRequest(e, http.MethodGet, "/users/2")
+	<-copied
+
+	if cp.Err() != nil {
+		t.Errorf("Err() = %v, want nil after the request is canceled", cp.Err())
+	}
+	if cp.Param("id") != "1" {
+		t.Errorf("Param(id) = %q, want 1", cp.Param("id"))
+	}
+	if value, _ := cp.Get("key"); value != "1" {
+		t.Errorf("Get(key) = %v, want 1", value)
+	}
+	if cp.W.Header().Get("X-Test") != "1" {
+		t.Errorf("header not copied: %v", cp.W.Header())
+	}
+	if _, err := cp.W.Write([]byte("late")); !errors.Is(err, ErrCopiedContextWrite) {
+		t.Errorf("Write error = %v, want ErrCopiedContextWrite", err)
+	}
+	if err := cp.String(http.StatusOK, "late"); !errors.Is(err, ErrCopiedContextWrite) {
+		t.Errorf("String error = %v, want ErrCopiedContextWrite", err)
+	}
+}
+
+type ctxKey struct{}
+
+func TestContextWithContext(t *testing.T) {
+	e := New()
+	var deadlineOK, doneOK bool
+	var value, fallback, missing any
+	var panicked any
+	e.Group("").Get("/", func(c *Context) {
+		c.Set("key", "set")
+
+		ctx, cancel := context.WithTimeout(context.WithValue(c.R.Context(), ctxKey{}, "ctx"), time.Minute)
+		defer cancel()
+		c.WithContext(ctx)
+		_, deadlineOK = c.Deadline()
+		cancel()
+		<-c.Done()
+		doneOK = errors.Is(c.Err(), context.Canceled)
+
+		value = c.Value(ctxKey{})
+		fallback = c.Value("key")
+		missing = c.Value("missing")
+
+		func() {
+			defer func() { panicked = recover() }()
+			c.WithContext(context.WithValue(c, ctxKey{}, "self"))
+		}()
+	})
+
+	performRequest(e, http.MethodGet, "/")
+	if !deadlineOK || !doneOK {
+		t.Errorf("deadline %v, canceled %v, want the ctx given to WithContext", deadlineOK, doneOK)
+	}
+	if value != "ctx" || fallback != "set" || missing != nil {
+		t.Errorf("Value = %v, %v, %v, want ctx, set, nil", value, fallback, missing)
+	}
+	if panicked == nil {
+		t.Error("WithContext with a ctx derived from c did not panic")
+	}
+}
+
+type validatedUser struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestAbortWithValidationErrors(t *testing.T) {
+	if err := binding.RegisterTranslations(fr.New(), map[string]string{"required": "{0} est obligatoire"}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.Group("").Post("/:status", func(c *Context) {
+		var user validatedUser
+		status, _ := strconv.Atoi(c.Param("status"))
+		c.AbortWithValidationErrors(status, c.ShouldBindWith(&user, binding.JSON))
+	})
+
+	tests := []struct {
+		status   int
+		body     string
+		language string
+		want     string
+	}{
+		{http.StatusBadRequest, `{}`, "", `{"errors":[{"field":"Name","json":"name","tag":"required","message":"name is a required field"}]}`},
+		{http.StatusUnprocessableEntity, `{}`, "de;q=0.9, fr-CH, en;q=0.5", `{"errors":[{"field":"Name","json":"name","tag":"required","message":"name est obligatoire"}]}`},
+		{http.StatusBadRequest, `{`, "", `{"error":"unexpected EOF"}`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/"+strconv.Itoa(tt.status), strings.NewReader(tt.body))
+		req.Header.Set("Accept-Language", tt.language)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != tt.status || strings.TrimSpace(w.Body.String()) != tt.want {
+			t.Errorf("%s %q: got %d %s, want %d %s", tt.body, tt.language, w.Code, w.Body, tt.status, tt.want)
+		}
+	}
+}
+
+func TestBindSelectsContentType(t *testing.T) {
+	type target struct {
+		Name string `json:"name" xml:"name" form:"name"`
+	}
+	e := New()
+	var got target
+	var bindErr error
+	e.Group("").Any("/", func(c *Context) {
+		got = target{}
+		bindErr = c.Bind(&got)
+	})
+
+	tests := []struct {
+		method      string
+		target      string
+		contentType string
+		body        string
+		status      int
+	}{
+		{http.MethodPost, "/", "application/json; charset=utf-8", `{"name":"sonata"}`, http.StatusOK},
+		{http.MethodPost, "/", binding.MIMEXML, `<target><name>sonata</name></target>`, http.StatusOK},
+		{http.MethodPost, "/", binding.MIMEPOSTForm, `name=sonata`, http.StatusOK},
+		{http.MethodGet, "/?name=sonata", "", ``, http.StatusOK},
+		{http.MethodPost, "/", binding.MIMEPlain, `sonata`, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s %q: status %d, want %d", tt.method, tt.contentType, w.Code, tt.status)
+		}
+		if tt.status == http.StatusOK && (bindErr != nil || got.Name != "sonata") {
+			t.Errorf("%s %q: got %+v, %v", tt.method, tt.contentType, got, bindErr)
+		}
+		if tt.status == http.StatusUnsupportedMediaType && !errors.Is(bindErr, binding.ErrUnsupportedMediaType) {
+			t.Errorf("%s %q: error %v, want ErrUnsupportedMediaType", tt.method, tt.contentType, bindErr)
+		}
+	}
+}
+
+type textBinding struct{}
+
+func (textBinding) Name() string { return "text" }
+
+func (textBinding) Bind(r *http.Request, obj any) error {
+	body, err := io.ReadAll(r.Body)
+	*obj.(*string) = string(body)
+	return err
+}
+
+func TestShouldBindRegistered(t *testing.T) {
+	binding.Register("text/csv", textBinding{})
+	e := New()
+	var got string
+	var bindErr error
+	e.Group("").Post("/", func(c *Context) {
+		bindErr = c.ShouldBind(&got)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("sonata"))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if bindErr != nil || got != "sonata" {
+		t.Errorf("ShouldBind = %q, %v, want the registered binding", got, bindErr)
+	}
+}
@@ -0,0 +1,56 @@
+package sonata
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+type RouteInfo struct {
+	Method          string
+	Path            string
+	Handler         string
+	MiddlewareCount int
+}
+
+type RoutesInfo []RouteInfo
+
+// Routes returns the registered routes in registration order.
+func (e *Engine) Routes() RoutesInfo {
+	routes := make(RoutesInfo, 0, len(e.routes))
+	for _, route := range e.routes {
+		routes = append(routes, route.info())
+	}
+	return routes
+}
+
+func (r *Route) info() RouteInfo {
+	return RouteInfo{
+		Method:          r.Method,
+		Path:            r.Path,
+		Handler:         nameOfFunction(r.handlers.Last()),
+		MiddlewareCount: len(r.handlers) - 1,
+	}
+}
+
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+func debugPrint(format string, values ...any) {
+	if !IsDebugging() {
+		return
+	}
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	fmt.Fprintf(DefaultWriter, "[sonata-debug] "+format, values...)
+}
+
+// debugPrintRoute prints a route when it is registered, so the route table
+// is printed in debug mode however the Engine is served.
+func debugPrintRoute(route RouteInfo) {
+	debugPrint("%-9s %-30s --> %s (%d middlewares)\n",
+		route.Method, route.Path, route.Handler, route.MiddlewareCount)
+}
@@ -0,0 +1,39 @@
+package sonata
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func init() {
+	SetMode(TestMode)
+}
+
+func TestDebugPrintRouteOnRegistration(t *testing.T) {
+	var out bytes.Buffer
+	defaultWriter := DefaultWriter
+	DefaultWriter = &out
+	SetMode(DebugMode)
+	defer func() {
+		DefaultWriter = defaultWriter
+		SetMode(TestMode)
+	}()
+
+	e := New()
+	e.Group("api").Get("/users/:id", stringHandler("user"))
+
+	got := out.String()
+	for _, want := range []string{"[sonata-debug] GET", "/api/users/:id", "(0 middlewares)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("debug output %q does not contain %q", got, want)
+		}
+	}
+
+	out.Reset()
+	SetMode(ReleaseMode)
+	e.Group("api").Post("/users", stringHandler("created"))
+	if out.Len() != 0 {
+		t.Errorf("release mode printed %q", out.String())
+	}
+}
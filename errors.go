@@ -0,0 +1,186 @@
+package sonata
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mneumi/sonata/render"
+)
+
+type ErrorType uint64
+
+const (
+	// ErrorTypeBind is used when binding the request fails.
+	ErrorTypeBind ErrorType = 1 << 63
+	// ErrorTypeRender is used when rendering the response fails.
+	ErrorTypeRender ErrorType = 1 << 62
+	// ErrorTypePrivate errors are not shown to the client.
+	ErrorTypePrivate ErrorType = 1 << 0
+	// ErrorTypePublic errors can be shown to the client.
+	ErrorTypePublic ErrorType = 1 << 1
+	// ErrorTypeAny matches every type in ByType.
+	ErrorTypeAny ErrorType = 1<<64 - 1
+)
+
+// Error is an error collected by Context.Error, with its type and optional
+// metadata.
+type Error struct {
+	Err  error
+	Type ErrorType
+	Meta any
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) SetType(flags ErrorType) *Error {
+	e.Type = flags
+	return e
+}
+
+func (e *Error) SetMeta(meta any) *Error {
+	e.Meta = meta
+	return e
+}
+
+func (e *Error) IsType(flags ErrorType) bool {
+	return e.Type&flags > 0
+}
+
+// JSON returns a JSON serializable representation of the error: its
+// metadata, or a map with the error message under "error".
+func (e *Error) JSON() any {
+	data := map[string]any{}
+	switch meta := e.Meta.(type) {
+	case nil:
+	case map[string]any:
+		for key, value := range meta {
+			data[key] = value
+		}
+	default:
+		data["meta"] = meta
+	}
+	if _, ok := data["error"]; !ok {
+		data["error"] = e.Error()
+	}
+	return data
+}
+
+type errorMsgs []*Error
+
+// ByType returns the errors matching one of the flags.
+func (a errorMsgs) ByType(flags ErrorType) errorMsgs {
+	if len(a) == 0 || flags == ErrorTypeAny {
+		return a
+	}
+	var result errorMsgs
+	for _, err := range a {
+		if err.IsType(flags) {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+func (a errorMsgs) Last() *Error {
+	if len(a) > 0 {
+		return a[len(a)-1]
+	}
+	return nil
+}
+
+func (a errorMsgs) Errors() []string {
+	messages := make([]string, 0, len(a))
+	for _, err := range a {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func (a errorMsgs) JSON() any {
+	switch len(a) {
+	case 0:
+		return nil
+	case 1:
+		return a.Last().JSON()
+	default:
+		data := make([]any, 0, len(a))
+		for _, err := range a {
+			data = append(data, err.JSON())
+		}
+		return data
+	}
+}
+
+func (a errorMsgs) String() string {
+	var b strings.Builder
+	for i, err := range a {
+		fmt.Fprintf(&b, "Error #%02d: %s\n", i+1, err.Err)
+		if err.Meta != nil {
+			fmt.Fprintf(&b, "     Meta: %v\n", err.Meta)
+		}
+	}
+	return b.String()
+}
+
+// HandleErrorFunc is a handler that returns its error instead of writing
+// the error response itself. Use WrapE to register it.
+type HandleErrorFunc func(ctx *Context) error
+
+// WrapE adapts a HandleErrorFunc to a HandleFunc. The returned error is
+// collected with Context.Error, so it is answered by Engine.ErrorHandler.
+func WrapE(handle HandleErrorFunc) HandleFunc {
+	return func(ctx *Context) {
+		if err := handle(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// defaultErrorHandler answers with the status already set if it is an error
+// status, or else with 500 Internal Server Error. The messages of the public
+// and binding errors are written; private errors only show the status text.
+func defaultErrorHandler(ctx *Context) {
+	if ctx.W.Written() {
+		return
+	}
+
+	status := ctx.W.Status()
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+		if ctx.Errors.Last().IsType(ErrorTypeBind) {
+			status = http.StatusBadRequest
+		}
+	}
+
+	detail := ""
+	if public := ctx.Errors.ByType(ErrorTypePublic | ErrorTypeBind); len(public) > 0 {
+		detail = strings.Join(public.Errors(), "\n")
+	}
+	serveError(ctx, status, detail)
+}
+
+// serveError writes the error responses of sonata: a problem details
+// document if Engine.UseProblemDetails is set, or else the detail, or the
+// status text, as plain text.
+func serveError(ctx *Context, status int, detail string) {
+	if ctx.engine.UseProblemDetails {
+		ctx.Problem(render.Problem{
+			Status:   status,
+			Detail:   detail,
+			Instance: ctx.R.URL.Path,
+		})
+		return
+	}
+
+	if detail == "" {
+		detail = http.StatusText(status)
+	}
+	ctx.String(status, detail)
+}
@@ -0,0 +1,132 @@
+package sonata
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWrapE(t *testing.T) {
+	e := New()
+	g := e.Group("")
+	g.Get("/ok", WrapE(func(ctx *Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	}))
+	g.Get("/private", WrapE(func(ctx *Context) error {
+		return errors.New("database down")
+	}))
+	g.Get("/public", WrapE(func(ctx *Context) error {
+		ctx.W.WriteHeader(http.StatusConflict)
+		ctx.Error(errors.New("name taken")).SetType(ErrorTypePublic)
+		return nil
+	}))
+	g.Get("/bind", WrapE(func(ctx *Context) error {
+		ctx.Error(errors.New("bad field")).SetType(ErrorTypeBind)
+		return nil
+	}))
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/ok", http.StatusOK, "ok"},
+		{"/private", http.StatusInternalServerError, "Internal Server Error"},
+		{"/public", http.StatusConflict, "name taken"},
+		{"/bind", http.StatusBadRequest, "bad field"},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, http.MethodGet, tt.path)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+}
+
+func TestErrorHandlerRunsBeforeMiddlewaresResume(t *testing.T) {
+	for _, abort := range []bool{false, true} {
+		e := New()
+		var seen int
+		e.Use(func(next HandleFunc) HandleFunc {
+			return func(ctx *Context) {
+				next(ctx)
+				seen = ctx.W.Status()
+			}
+		})
+		g := e.Group("")
+		if abort {
+			// The usual authentication middleware: record the error and stop.
+			g.Use(func(next HandleFunc) HandleFunc {
+				return func(ctx *Context) {
+					ctx.Error(errors.New("unauthorized"))
+					ctx.Abort()
+				}
+			})
+		}
+		g.Get("/", func(ctx *Context) {
+			ctx.Error(errors.New("failed"))
+		})
+
+		w := performRequest(e, http.MethodGet, "/")
+		if w.Code != http.StatusInternalServerError || seen != http.StatusInternalServerError {
+			t.Errorf("abort=%v: middleware saw %d, client got %d, want 500 for both", abort, seen, w.Code)
+		}
+	}
+}
+
+func TestErrorHandlerNil(t *testing.T) {
+	e := New()
+	e.ErrorHandler = nil
+	e.Group("").Get("/", func(ctx *Context) {
+		ctx.Error(errors.New("failed"))
+		ctx.String(http.StatusOK, "handled by the handler")
+	})
+	w := performRequest(e, http.MethodGet, "/")
+	if w.Code != http.StatusOK || w.Body.String() != "handled by the handler" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorMsgs(t *testing.T) {
+	private := &Error{Err: errors.New("private"), Type: ErrorTypePrivate}
+	public := &Error{Err: errors.New("public"), Type: ErrorTypePublic, Meta: map[string]any{"field": "name"}}
+	bind := &Error{Err: errors.New("bind"), Type: ErrorTypeBind, Meta: 42}
+	errs := errorMsgs{private, public, bind}
+
+	if got := errs.ByType(ErrorTypePublic | ErrorTypeBind); !reflect.DeepEqual(got, errorMsgs{public, bind}) {
+		t.Errorf("ByType(public|bind) = %v", got)
+	}
+	if got := errs.ByType(ErrorTypeAny); len(got) != 3 {
+		t.Errorf("ByType(any) = %v", got)
+	}
+	if got := errs.ByType(ErrorTypeRender); got != nil {
+		t.Errorf("ByType(render) = %v, want nil", got)
+	}
+	if errs.Last() != bind || errorMsgs(nil).Last() != nil {
+		t.Errorf("Last() = %v", errs.Last())
+	}
+	if got := errs.Errors(); !reflect.DeepEqual(got, []string{"private", "public", "bind"}) {
+		t.Errorf("Errors() = %v", got)
+	}
+
+	want := []any{
+		map[string]any{"error": "private"},
+		map[string]any{"error": "public", "field": "name"},
+		map[string]any{"error": "bind", "meta": 42},
+	}
+	if got := errs.JSON(); !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON() = %v, want %v", got, want)
+	}
+	if got := (errorMsgs{public}).JSON(); !reflect.DeepEqual(got, want[1]) {
+		t.Errorf("JSON() of one error = %v, want %v", got, want[1])
+	}
+	if got := errorMsgs(nil).JSON(); got != nil {
+		t.Errorf("JSON() of no error = %v, want nil", got)
+	}
+
+	wrapped := &Error{Err: http.ErrNoCookie}
+	if !errors.Is(wrapped, http.ErrNoCookie) {
+		t.Errorf("errors.Is does not see through Error")
+	}
+}
@@ -33,6 +33,7 @@ type LogFormatterParams struct {
 	Request        *http.Request
 	TimeStamp      time.Time
 	StatusCode     int
+	BodySize       int
 	Latency        time.Duration
 	ClientIP       net.IP
 	Method         string
@@ -112,7 +113,7 @@ func LoggingWithConfig(conf *LoggingConfig, next HandleFunc) HandleFunc {
 		ip, _, _ := net.SplitHostPort(strings.TrimSpace(ctx.R.RemoteAddr))
 		clientIP := net.ParseIP(ip)
 		method := r.Method
-		statusCode := ctx.StatusCode
+		statusCode := ctx.W.Status()
 
 		if raw != "" {
 			path = path + "?" + raw
@@ -120,6 +121,7 @@ func LoggingWithConfig(conf *LoggingConfig, next HandleFunc) HandleFunc {
 
 		param.TimeStamp = stop
 		param.StatusCode = statusCode
+		param.BodySize = ctx.W.Size()
 		param.Latency = latency
 		param.Path = path
 		param.ClientIP = clientIP
@@ -0,0 +1,37 @@
+package sonata
+
+import "os"
+
+const EnvSonataMode = "SONATA_MODE"
+
+const (
+	DebugMode   = "debug"
+	ReleaseMode = "release"
+	TestMode    = "test"
+)
+
+var sonataMode = DebugMode
+
+func init() {
+	SetMode(os.Getenv(EnvSonataMode))
+}
+
+// SetMode sets the mode of sonata. An empty mode means DebugMode.
+func SetMode(mode string) {
+	switch mode {
+	case "", DebugMode:
+		sonataMode = DebugMode
+	case ReleaseMode, TestMode:
+		sonataMode = mode
+	default:
+		panic("err: unknown sonata mode: " + mode)
+	}
+}
+
+func Mode() string {
+	return sonataMode
+}
+
+func IsDebugging() bool {
+	return sonataMode == DebugMode
+}
@@ -0,0 +1,75 @@
+package sonata
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const mountParam = "mountpath"
+
+// WrapH adapts an http.Handler to a HandleFunc.
+func WrapH(h http.Handler) HandleFunc {
+	return func(ctx *Context) {
+		h.ServeHTTP(ctx.W, ctx.R)
+	}
+}
+
+// WrapF adapts an http.HandlerFunc to a HandleFunc.
+func WrapF(f http.HandlerFunc) HandleFunc {
+	return func(ctx *Context) {
+		f(ctx.W, ctx.R)
+	}
+}
+
+// Mount serves every request under prefix with handler, whatever the method.
+// The handler sees the request path with the group path and prefix
+// stripped, so a sub-engine can be mounted as is:
+//
+//	g.Mount("/admin", adminEngine)
+//
+// Handlers that route on the full path, like net/http/pprof, must be
+// registered with WrapH instead, which keeps the path:
+//
+//	g.Any("/debug/pprof/*path", WrapH(http.DefaultServeMux))
+//
+// The middlewares of the group still run before handler.
+func (rg *routerGroup) Mount(prefix string, handler http.Handler, middlewareFunc ...MiddlewareFunc) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	handle := func(ctx *Context) {
+		path := "/"
+		if value, ok := ctx.params.Get(mountParam); ok {
+			path = value
+		}
+		handler.ServeHTTP(ctx.W, stripPrefix(ctx.R, path))
+	}
+
+	rg.Any(prefix, handle, middlewareFunc...)
+	rg.Any(prefix+"/*"+mountParam, handle, middlewareFunc...)
+}
+
+// stripPrefix returns a shallow copy of r whose URL path is path, the
+// trailing part of the original path.
+func stripPrefix(r *http.Request, path string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+
+	if rawPath := r.URL.RawPath; rawPath != "" {
+		for i := 0; i < len(rawPath); i++ {
+			if rawPath[i] != '/' {
+				continue
+			}
+			if unescaped, err := url.PathUnescape(rawPath[i:]); err == nil && unescaped == path {
+				r2.URL.RawPath = rawPath[i:]
+				break
+			}
+		}
+	}
+
+	return r2
+}
@@ -0,0 +1,202 @@
+package sonata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mneumi/sonata/binding"
+	"github.com/mneumi/sonata/render"
+)
+
+var ErrNotAcceptable = errors.New("the accepted formats are not offered by the server")
+
+// Negotiate holds the data of each format offered by Context.Negotiate.
+// Data is used for the formats without specific data.
+type Negotiate struct {
+	// Offered restricts the formats to negotiate. Only the media types
+	// Negotiate can render are used: application/json, application/xml,
+	// text/xml, text/html and text/plain. By default every format with data
+	// is offered.
+	Offered  []string
+	HTMLName string
+	HTMLData any
+	JSONData any
+	XMLData  any
+	Data     any
+}
+
+type acceptedType struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept returns the media ranges of an Accept header with their
+// quality. Ranges with an invalid quality are ignored.
+func parseAccept(accept string) []acceptedType {
+	var accepted []acceptedType
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType == "" {
+			continue
+		}
+
+		quality := 1.0
+		valid := true
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			quality = q
+		}
+		if valid {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, quality: quality})
+		}
+	}
+	return accepted
+}
+
+// acceptedLanguages returns the locales of the Accept-Language header of
+// the request by decreasing quality, e.g. zh_cn and zh for zh-CN.
+func (c *Context) acceptedLanguages() []string {
+	accepted := parseAccept(c.R.Header.Get("Accept-Language"))
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	var languages []string
+	for _, language := range accepted {
+		if language.quality == 0 || language.mediaType == "*" {
+			continue
+		}
+		locale := strings.ReplaceAll(language.mediaType, "-", "_")
+		languages = append(languages, locale)
+		if base, _, ok := strings.Cut(locale, "_"); ok {
+			languages = append(languages, base)
+		}
+	}
+	return languages
+}
+
+// specificity returns how precisely mediaRange matches mediaType: 3 for the
+// same type, 2 for a type/* range, 1 for */*, 0 if it does not match.
+func specificity(mediaRange string, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 3
+	case mediaRange == "*/*":
+		return 1
+	case strings.HasSuffix(mediaRange, "/*"):
+		if strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1]) {
+			return 2
+		}
+	}
+	return 0
+}
+
+// NegotiateFormat returns the offered media type the client prefers,
+// according to the quality values and wildcards of its Accept header. Ties
+// are broken by the order of offered. Without Accept header, the first
+// offered type is returned; if no offered type is acceptable, "" is.
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		panic("err: NegotiateFormat requires at least one offered type")
+	}
+
+	accepted := parseAccept(c.R.Header.Get("Accept"))
+	if len(accepted) == 0 {
+		return offered[0]
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offered {
+		mediaType := strings.ToLower(offer)
+		quality, matched := 0.0, 0
+		for _, accept := range accepted {
+			if s := specificity(accept.mediaType, mediaType); s > matched {
+				quality, matched = accept.quality, s
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
+
+// Negotiate renders the data of the format the client prefers among JSON,
+// XML, HTML and plain text, where Data is written with fmt.Sprint. If none
+// is acceptable, the request is aborted with 406 Not Acceptable.
+func (c *Context) Negotiate(status int, config Negotiate) error {
+	var offered []string
+	for _, offer := range config.Offered {
+		if isNegotiable(offer) {
+			offered = append(offered, strings.ToLower(offer))
+		}
+	}
+	if len(config.Offered) == 0 {
+		if config.JSONData != nil || config.Data != nil {
+			offered = append(offered, binding.MIMEJSON)
+		}
+		if config.XMLData != nil || config.Data != nil {
+			offered = append(offered, binding.MIMEXML, binding.MIMEXML2)
+		}
+		if config.HTMLName != "" {
+			offered = append(offered, binding.MIMEHTML)
+		}
+		if config.Data != nil {
+			offered = append(offered, binding.MIMEPlain)
+		}
+	}
+
+	format := ""
+	if len(offered) > 0 {
+		format = c.NegotiateFormat(offered...)
+	}
+
+	switch format {
+	case binding.MIMEJSON:
+		return c.JSON(status, chooseData(config.JSONData, config.Data))
+	case binding.MIMEXML, binding.MIMEXML2:
+		return c.XML(status, chooseData(config.XMLData, config.Data))
+	case binding.MIMEHTML:
+		return c.Render(status, &render.HTML{
+			Name:       config.HTMLName,
+			Data:       chooseData(config.HTMLData, config.Data),
+			IsTemplate: true,
+			Template:   c.engine.htmlRender.Template,
+		})
+	case binding.MIMEPlain:
+		return c.String(status, "%s", fmt.Sprint(config.Data))
+	default:
+		c.Abort()
+		c.W.WriteHeader(http.StatusNotAcceptable)
+		c.Error(ErrNotAcceptable).SetType(ErrorTypePublic)
+		return ErrNotAcceptable
+	}
+}
+
+func isNegotiable(mediaType string) bool {
+	switch strings.ToLower(mediaType) {
+	case binding.MIMEJSON, binding.MIMEXML, binding.MIMEXML2, binding.MIMEHTML, binding.MIMEPlain:
+		return true
+	}
+	return false
+}
+
+func chooseData(custom any, wildcard any) any {
+	if custom != nil {
+		return custom
+	}
+	return wildcard
+}
@@ -0,0 +1,50 @@
+package sonata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		offered     []string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{[]string{"text/plain"}, "text/plain", http.StatusOK, "text/plain; charset=utf-8", "hi"},
+		{[]string{"TEXT/PLAIN"}, "text/*", http.StatusOK, "text/plain; charset=utf-8", "hi"},
+		{nil, "text/plain", http.StatusOK, "text/plain; charset=utf-8", "hi"},
+		{nil, "application/json", http.StatusOK, "application/json; charset=utf8", `"hi"`},
+		{nil, "", http.StatusOK, "application/json; charset=utf8", `"hi"`},
+		{[]string{"application/json", "text/plain"}, "text/plain;q=0.5, application/json", http.StatusOK, "application/json; charset=utf8", `"hi"`},
+		{[]string{"text/csv", "text/plain"}, "text/csv", http.StatusNotAcceptable, "", ""},
+		{[]string{"text/csv"}, "*/*", http.StatusNotAcceptable, "", ""},
+		{nil, "image/png", http.StatusNotAcceptable, "", ""},
+	}
+	for _, tt := range tests {
+		e := New()
+		e.Group("").Get("/", func(ctx *Context) {
+			ctx.Negotiate(http.StatusOK, Negotiate{Offered: tt.offered, Data: "hi"})
+		})
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("Offered %v, Accept %q: status %d, want %d", tt.offered, tt.accept, w.Code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != tt.contentType || w.Body.String() != tt.body {
+			t.Errorf("Offered %v, Accept %q: %s %q, want %s %q", tt.offered, tt.accept, contentType, w.Body.String(), tt.contentType, tt.body)
+		}
+	}
+}
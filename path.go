@@ -0,0 +1,30 @@
+package sonata
+
+import "path"
+
+// cleanPath returns the canonical form of p: it begins with a single '/',
+// contains no duplicate slashes, "." or ".." elements, and keeps the
+// trailing slash of p.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// toggleTrailingSlash adds the trailing slash to p, or removes it if p
+// already has one.
+func toggleTrailingSlash(p string) string {
+	if len(p) > 1 && p[len(p)-1] == '/' {
+		return p[:len(p)-1]
+	}
+	return p + "/"
+}
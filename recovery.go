@@ -0,0 +1,166 @@
+package sonata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const maxStackDepth = 32
+
+var defaultSensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+type RecoveryFunc func(ctx *Context, err any)
+
+type RecoveryConfig struct {
+	// Handler writes the response after a panic. By default the request is
+	// answered with 500 Internal Server Error, unless the response is
+	// already written.
+	Handler RecoveryFunc
+	// Output receives the panic logs. It defaults to DefaultErrorWriter.
+	Output io.Writer
+	// SensitiveHeaders are redacted in the request dump, in addition to the
+	// authorization and cookie headers.
+	SensitiveHeaders []string
+}
+
+var DefaultErrorWriter io.Writer = os.Stderr
+
+func Recovery() MiddlewareFunc {
+	return RecoveryWithConfig(&RecoveryConfig{})
+}
+
+// RecoveryWithConfig returns a middleware that recovers from panics in the
+// handlers that follow it, logs the panic with the request and a stack
+// trace, and lets conf.Handler answer the request. Panics caused by a client
+// closing the connection are logged on a single line and abort the request.
+func RecoveryWithConfig(conf *RecoveryConfig) MiddlewareFunc {
+	handler := conf.Handler
+	if handler == nil {
+		handler = defaultRecoveryHandler
+	}
+	out := conf.Output
+	if out == nil {
+		out = DefaultErrorWriter
+	}
+	sensitiveHeaders := append(append([]string{}, defaultSensitiveHeaders...), conf.SensitiveHeaders...)
+
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				// http.ErrAbortHandler aborts the response on purpose, e.g.
+				// from httputil.ReverseProxy; let the server handle it.
+				if e, ok := err.(error); ok && errors.Is(e, http.ErrAbortHandler) {
+					panic(err)
+				}
+
+				timeStamp := time.Now().Format("2006/01/02 - 15:04:05")
+				if isBrokenPipe(err) {
+					fmt.Fprintf(out, "[Recovery] %s connection closed by client: %s %s: %v\n",
+						timeStamp, ctx.R.Method, ctx.R.URL.Path, err)
+					ctx.Abort()
+					return
+				}
+
+				fmt.Fprintf(out, "[Recovery] %s panic recovered:\n%s\n%v\n%s\n",
+					timeStamp, dumpRequest(ctx.R, sensitiveHeaders), err, stack(4))
+				handler(ctx, err)
+			}()
+
+			next(ctx)
+		}
+	}
+}
+
+func defaultRecoveryHandler(ctx *Context, err any) {
+	ctx.Abort()
+	if !ctx.W.Written() {
+		serveError(ctx, http.StatusInternalServerError, "")
+	}
+}
+
+// isBrokenPipe reports whether err is caused by a connection closed by the
+// client, in which case nothing can be written to it anymore.
+func isBrokenPipe(err any) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(e, &opErr) {
+		var syscallErr *os.SyscallError
+		if errors.As(opErr, &syscallErr) {
+			msg := strings.ToLower(syscallErr.Error())
+			return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+		}
+	}
+	return false
+}
+
+// dumpRequest returns the request line and headers of r, with the values of
+// sensitiveHeaders replaced by '*'.
+func dumpRequest(r *http.Request, sensitiveHeaders []string) string {
+	dump, err := httputil.DumpRequest(r, false)
+	if err != nil {
+		return err.Error()
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(dump)), "\r\n")
+	for i, line := range lines {
+		name, _, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		for _, header := range sensitiveHeaders {
+			if strings.EqualFold(name, header) {
+				lines[i] = name + ": *"
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stack returns the stack trace of the panic, without the frames of the
+// runtime, of the recovery middleware itself and of the HTTP server below
+// Engine.ServeHTTP.
+func stack(skip int) []byte {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b bytes.Buffer
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			fmt.Fprintf(&b, "%s:%d\n\t%s\n", frame.File, frame.Line, frame.Function)
+		}
+		if !more || strings.HasSuffix(frame.Function, ".(*Engine).ServeHTTP") {
+			break
+		}
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,124 @@
+package sonata
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func newRecoveryEngine(conf *RecoveryConfig, handle HandleFunc) *Engine {
+	e := New()
+	e.Use(RecoveryWithConfig(conf))
+	e.Group("").Get("/panic", handle)
+	return e
+}
+
+func TestRecovery(t *testing.T) {
+	var out bytes.Buffer
+	e := newRecoveryEngine(&RecoveryConfig{Output: &out}, func(ctx *Context) {
+		panic("boom")
+	})
+	r := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "Internal Server Error" {
+		t.Errorf("got %d %q, want 500 Internal Server Error", w.Code, w.Body.String())
+	}
+	log := out.String()
+	for _, want := range []string{"panic recovered", "GET /panic", "Authorization: *", "boom", "recovery_test.go"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log does not contain %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "secret") {
+		t.Errorf("log leaks the Authorization header:\n%s", log)
+	}
+}
+
+func TestRecoveryWrittenResponse(t *testing.T) {
+	e := newRecoveryEngine(&RecoveryConfig{Output: &bytes.Buffer{}}, func(ctx *Context) {
+		ctx.String(http.StatusAccepted, "partial")
+		panic("boom")
+	})
+	w := performRequest(e, http.MethodGet, "/panic")
+	if w.Code != http.StatusAccepted || w.Body.String() != "partial" {
+		t.Errorf("got %d %q, want the written 202 %q", w.Code, w.Body.String(), "partial")
+	}
+}
+
+func TestRecoveryCustomHandler(t *testing.T) {
+	var recovered any
+	conf := &RecoveryConfig{
+		Output: &bytes.Buffer{},
+		Handler: func(ctx *Context, err any) {
+			recovered = err
+			ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, map[string]any{"error": err})
+		},
+	}
+	e := newRecoveryEngine(conf, func(ctx *Context) {
+		panic("boom")
+	})
+	w := performRequest(e, http.MethodGet, "/panic")
+	if recovered != "boom" || w.Code != http.StatusServiceUnavailable || w.Body.String() != `{"error":"boom"}` {
+		t.Errorf("got %v, %d %q", recovered, w.Code, w.Body.String())
+	}
+}
+
+func TestRecoveryBrokenPipe(t *testing.T) {
+	var out bytes.Buffer
+	handlerCalled := false
+	conf := &RecoveryConfig{
+		Output: &out,
+		Handler: func(ctx *Context, err any) {
+			handlerCalled = true
+		},
+	}
+	e := newRecoveryEngine(conf, func(ctx *Context) {
+		panic(&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)})
+	})
+	performRequest(e, http.MethodGet, "/panic")
+	if handlerCalled {
+		t.Errorf("the recovery handler ran for a broken pipe")
+	}
+	if log := out.String(); !strings.Contains(log, "connection closed by client") || strings.Count(log, "\n") != 1 {
+		t.Errorf("broken pipe log = %q, want a single line", log)
+	}
+}
+
+func TestRecoveryErrAbortHandler(t *testing.T) {
+	e := newRecoveryEngine(&RecoveryConfig{Output: &bytes.Buffer{}}, func(ctx *Context) {
+		panic(http.ErrAbortHandler)
+	})
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler to be re-panicked", err)
+		}
+	}()
+	performRequest(e, http.MethodGet, "/panic")
+	t.Errorf("http.ErrAbortHandler was swallowed")
+}
+
+func TestDumpRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/login?next=/", nil)
+	r.Header.Set("Authorization", "Basic c2VjcmV0")
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("X-Custom-Token", "secret")
+	r.Header.Set("X-Request-Id", "42")
+
+	dump := dumpRequest(r, append(defaultSensitiveHeaders, "X-Custom-Token"))
+	if strings.Contains(dump, "secret") || strings.Contains(dump, "c2VjcmV0") {
+		t.Errorf("dump leaks a sensitive header:\n%s", dump)
+	}
+	for _, want := range []string{"POST /login?next=/ HTTP/1.1", "Authorization: *", "Cookie: *", "X-Custom-Token: *", "X-Request-Id: 42"} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump does not contain %q:\n%s", want, dump)
+		}
+	}
+}
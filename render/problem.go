@@ -0,0 +1,181 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ProblemXMLNamespace is the XML namespace of problem documents.
+const ProblemXMLNamespace = "urn:ietf:rfc:7807"
+
+// Problem is a problem details document as defined by RFC 9457. The
+// Extensions members are serialized next to the standard members.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+func (p *Problem) members() map[string]any {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	if p.Type != "" {
+		members["type"] = p.Type
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return members
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.members())
+}
+
+// MarshalXML encodes the problem in the XML format of RFC 9457: every
+// member is an element, arrays hold their values in <i> elements and
+// objects hold their members as child elements. Extension values are
+// converted through their JSON form, so they follow their json tags.
+func (p *Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Space: ProblemXMLNamespace, Local: "problem"}
+	start.Attr = nil
+
+	// Decoding the JSON form turns every value into a string, number, bool,
+	// nil, []any or map[string]any.
+	data, err := json.Marshal(p.members())
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var members map[string]any
+	if err := decoder.Decode(&members); err != nil {
+		return err
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeXMLMembers(e, members); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeXMLMembers(e *xml.Encoder, members map[string]any) error {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		if !isXMLName(key) {
+			return fmt.Errorf("render: problem member %q is not a valid XML name", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := encodeXMLValue(e, key, members[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeXMLValue(e *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	var err error
+	switch value := value.(type) {
+	case nil:
+	case map[string]any:
+		err = encodeXMLMembers(e, value)
+	case []any:
+		for _, item := range value {
+			if err = encodeXMLValue(e, "i", item); err != nil {
+				break
+			}
+		}
+	default:
+		err = e.EncodeToken(xml.CharData(fmt.Sprint(value)))
+	}
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// isXMLName reports whether name can be used as an element name: a letter
+// or '_' followed by letters, digits, '-', '_' or '.', and not starting with
+// "xml", which is reserved.
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type ProblemJSON struct {
+	Data *Problem
+}
+
+func (p *ProblemJSON) Render(w http.ResponseWriter) error {
+	jsonData, err := json.Marshal(p.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonData)
+	return err
+}
+
+func (p *ProblemJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/problem+json; charset=utf-8")
+}
+
+func (p *ProblemJSON) WriteHeader(status int, w http.ResponseWriter) {
+	w.WriteHeader(status)
+}
+
+type ProblemXML struct {
+	Data *Problem
+}
+
+func (p *ProblemXML) Render(w http.ResponseWriter) error {
+	return xml.NewEncoder(w).Encode(p.Data)
+}
+
+func (p *ProblemXML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, "application/problem+xml; charset=utf-8")
+}
+
+func (p *ProblemXML) WriteHeader(status int, w http.ResponseWriter) {
+	w.WriteHeader(status)
+}
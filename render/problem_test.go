@@ -0,0 +1,52 @@
+package render
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemXML(t *testing.T) {
+	problem := &Problem{
+		Title:  "Bad Request",
+		Status: 400,
+		Extensions: map[string]any{
+			"errors": []string{"a", "b"},
+			"ctx":    map[string]int{"x": 1},
+			"empty":  nil,
+			"field": struct {
+				Name string `json:"name"`
+			}{Name: "sku"},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	if err := (&ProblemXML{Data: problem}).Render(w); err != nil {
+		t.Fatal(err)
+	}
+	want := `<problem xmlns="urn:ietf:rfc:7807">` +
+		`<ctx><x>1</x></ctx>` +
+		`<empty></empty>` +
+		`<errors><i>a</i><i>b</i></errors>` +
+		`<field><name>sku</name></field>` +
+		`<status>400</status>` +
+		`<title>Bad Request</title>` +
+		`</problem>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestProblemXMLInvalidName(t *testing.T) {
+	for _, key := range []string{"1st", "has space", "a:b", "xmlns", ""} {
+		problem := &Problem{Status: 400, Extensions: map[string]any{key: "v"}}
+		if _, err := xml.Marshal(problem); err == nil {
+			t.Errorf("extension %q: Marshal should fail", key)
+		}
+	}
+
+	nested := &Problem{Extensions: map[string]any{"ctx": map[string]int{"a b": 1}}}
+	if _, err := xml.Marshal(nested); err == nil {
+		t.Errorf("nested key %q: Marshal should fail", "a b")
+	}
+}
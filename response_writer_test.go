@@ -0,0 +1,96 @@
+package sonata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var w responseWriter
+	w.reset(rec)
+
+	if w.Written() || w.Size() != noWritten || w.Status() != http.StatusOK {
+		t.Fatalf("new writer: written %v, size %d, status %d", w.Written(), w.Size(), w.Status())
+	}
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusAccepted)
+	if w.Written() || rec.Code != http.StatusOK || w.Status() != http.StatusAccepted {
+		t.Errorf("WriteHeader sent the header: written %v, sent %d, status %d", w.Written(), rec.Code, w.Status())
+	}
+
+	w.Write([]byte("ab"))
+	w.WriteString("cd")
+	w.ReadFrom(strings.NewReader("ef"))
+	if !w.Written() || w.Size() != 6 || rec.Code != http.StatusAccepted || rec.Body.String() != "abcdef" {
+		t.Errorf("after writes: written %v, size %d, sent %d %q", w.Written(), w.Size(), rec.Code, rec.Body)
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+	if w.Status() != http.StatusAccepted {
+		t.Errorf("status changed to %d after the header was sent", w.Status())
+	}
+}
+
+func TestResponseWriterWriteHeaderNow(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var w responseWriter
+	w.reset(rec)
+	w.WriteHeader(http.StatusNoContent)
+	w.WriteHeaderNow()
+	if !w.Written() || w.Size() != 0 || rec.Code != http.StatusNoContent {
+		t.Errorf("written %v, size %d, sent %d", w.Written(), w.Size(), rec.Code)
+	}
+}
+
+func TestLoggingStatus(t *testing.T) {
+	var logged *LogFormatterParams
+	e := New()
+	e.Use(func(next HandleFunc) HandleFunc {
+		return LoggingWithConfig(&LoggingConfig{
+			Formatter: func(params *LogFormatterParams) string {
+				logged = params
+				return ""
+			},
+		}, next)
+	})
+	g := e.Group("")
+	g.Get("/created", func(ctx *Context) {
+		ctx.W.WriteHeader(http.StatusCreated)
+		ctx.W.Write([]byte("created"))
+	})
+	g.Get("/redirect", func(ctx *Context) {
+		http.Redirect(ctx.W, ctx.R, "/created", http.StatusFound)
+	})
+	g.Get("/error", func(ctx *Context) {
+		http.Error(ctx.W, "teapot", http.StatusTeapot)
+	})
+	g.Get("/string", stringHandler("ok"))
+
+	tests := []struct {
+		path   string
+		status int
+		size   int
+	}{
+		{"/created", http.StatusCreated, len("created")},
+		{"/redirect", http.StatusFound, -1},
+		{"/error", http.StatusTeapot, len("teapot\n")},
+		{"/string", http.StatusOK, len("ok")},
+		{"/missing", http.StatusNotFound, -1},
+	}
+	for _, tt := range tests {
+		logged = nil
+		w := performRequest(e, http.MethodGet, tt.path)
+		if logged == nil {
+			t.Fatalf("GET %s was not logged", tt.path)
+		}
+		if logged.StatusCode != tt.status || w.Code != tt.status {
+			t.Errorf("GET %s: logged %d, sent %d, want %d", tt.path, logged.StatusCode, w.Code, tt.status)
+		}
+		if tt.size >= 0 && logged.BodySize != tt.size {
+			t.Errorf("GET %s: logged size %d, want %d", tt.path, logged.BodySize, tt.size)
+		}
+	}
+}
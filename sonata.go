@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"text/template"
 
@@ -16,77 +18,173 @@ type HandleFunc func(ctx *Context)
 
 type MiddlewareFunc func(HandleFunc) HandleFunc
 
+// HandlersChain is the list of handlers run for a request: the middlewares
+// of the engine and groups, then the route middlewares, then the handler.
+type HandlersChain []HandleFunc
+
+func (c HandlersChain) Last() HandleFunc {
+	if len(c) > 0 {
+		return c[len(c)-1]
+	}
+	return nil
+}
+
+// WrapMiddleware adapts a MiddlewareFunc to a handler of the chain. The rest
+// of the chain runs when the middleware calls its next handler; if it does
+// not, the chain is aborted.
+func WrapMiddleware(middlewareFunc MiddlewareFunc) HandleFunc {
+	return func(ctx *Context) {
+		called := false
+		middlewareFunc(func(ctx *Context) {
+			called = true
+			ctx.Next()
+		})(ctx)
+		if !called {
+			ctx.Abort()
+		}
+	}
+}
+
+func wrapMiddlewares(middlewareFuncs []MiddlewareFunc) HandlersChain {
+	handlers := make(HandlersChain, len(middlewareFuncs))
+	for i, middlewareFunc := range middlewareFuncs {
+		handlers[i] = WrapMiddleware(middlewareFunc)
+	}
+	return handlers
+}
+
+func combineHandlers(chains ...HandlersChain) HandlersChain {
+	size := 0
+	for _, chain := range chains {
+		size += len(chain)
+	}
+	merged := make(HandlersChain, 0, size)
+	for _, chain := range chains {
+		merged = append(merged, chain...)
+	}
+	return merged
+}
+
 type routerGroup struct {
-	name                     string
-	handleFuncMap            map[string]map[string]HandleFunc
-	middlewaresHandleFuncMap map[string]map[string][]MiddlewareFunc
-	middlewares              []MiddlewareFunc
+	name     string
+	basePath string
+	parent   *routerGroup
+	handlers HandlersChain
+	engine   *Engine
 }
 
+// Use adds middlewares to the group. The handler chains are built when a
+// route is registered, so Use only applies to the routes registered after
+// it.
 func (rg *routerGroup) Use(middlewareFuncs ...MiddlewareFunc) {
-	rg.middlewares = append(rg.middlewares, middlewareFuncs...)
+	rg.handlers = append(rg.handlers, wrapMiddlewares(middlewareFuncs)...)
+}
+
+// UseHandlers adds handlers to the group that drive the chain themselves
+// with Context.Next and Context.Abort.
+func (rg *routerGroup) UseHandlers(handles ...HandleFunc) {
+	rg.handlers = append(rg.handlers, handles...)
 }
 
-func (rg *routerGroup) methodHandle(ctx *Context, name string, method string, handle HandleFunc) {
-	middlewareFuncs := rg.middlewaresHandleFuncMap[name][method]
-	for index := len(middlewareFuncs) - 1; index >= 0; index-- {
-		handle = middlewareFuncs[index](handle)
+// groupHandlers returns the handlers of the engine and of every group from
+// the root down to rg.
+func (rg *routerGroup) groupHandlers() HandlersChain {
+	if rg.parent == nil {
+		return combineHandlers(rg.engine.handlers, rg.handlers)
 	}
-	for index := len(rg.middlewares) - 1; index >= 0; index-- {
-		handle = rg.middlewares[index](handle)
+	return combineHandlers(rg.parent.groupHandlers(), rg.handlers)
+}
+
+func (rg *routerGroup) handle(name string, method string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	path := joinPaths(rg.basePath, name)
+	handlers := combineHandlers(rg.groupHandlers(), wrapMiddlewares(middlewareFunc), HandlersChain{handleFunc})
+	rg.engine.addRoute(method, path, handlers)
+
+	route := &Route{
+		Method:   method,
+		Path:     path,
+		engine:   rg.engine,
+		handlers: handlers,
 	}
+	rg.engine.routes = append(rg.engine.routes, route)
+	if IsDebugging() {
+		debugPrintRoute(route.info())
+	}
+	return route
+}
 
-	handle(ctx)
+// Group creates a child group under prefix. The child inherits the path
+// prefix and the middlewares of rg, so a route runs the parent middlewares,
+// then the child middlewares, then its own.
+func (rg *routerGroup) Group(prefix string, middlewareFuncs ...MiddlewareFunc) *routerGroup {
+	group := rg.engine.newRouterGroup(prefix, joinPaths(rg.basePath, prefix), rg)
+	group.Use(middlewareFuncs...)
+	return group
 }
 
-func (rg *routerGroup) handle(name string, method string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) {
-	_, ok := rg.handleFuncMap[name]
-	if !ok {
-		rg.handleFuncMap[name] = make(map[string]HandleFunc)
-		rg.middlewaresHandleFuncMap[name] = make(map[string][]MiddlewareFunc)
-	}
-	_, ok = rg.handleFuncMap[name][method]
-	if ok {
-		panic("err: register same route")
+// Handle registers a route for an arbitrary HTTP method.
+func (rg *routerGroup) Handle(method string, name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	if method == "" {
+		panic("err: HTTP method can not be empty")
 	}
-	rg.handleFuncMap[name][method] = handleFunc
-	rg.middlewaresHandleFuncMap[name][method] = append(rg.middlewaresHandleFuncMap[name][method], middlewareFunc...)
+	return rg.handle(name, method, handleFunc, middlewareFunc...)
 }
 
-func (rg *routerGroup) Any(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) {
-	rg.handle(name, AnyMethod, handleFunc, middlewareFunc...)
+func (rg *routerGroup) Any(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, AnyMethod, handleFunc, middlewareFunc...)
 }
 
-func (rg *routerGroup) Get(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) {
-	rg.handle(name, http.MethodGet, handleFunc, middlewareFunc...)
+func (rg *routerGroup) Get(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodGet, handleFunc, middlewareFunc...)
 }
 
-func (rg *routerGroup) Post(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) {
-	rg.handle(name, http.MethodPost, handleFunc, middlewareFunc...)
+func (rg *routerGroup) Post(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodPost, handleFunc, middlewareFunc...)
 }
 
-func (rg *routerGroup) Put(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) {
-	rg.handle(name, http.MethodPut, handleFunc, middlewareFunc...)
+func (rg *routerGroup) Put(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodPut, handleFunc, middlewareFunc...)
 }
 
-func (rg *routerGroup) Delete(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) {
-	rg.handle(name, http.MethodDelete, handleFunc, middlewareFunc...)
+func (rg *routerGroup) Delete(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodDelete, handleFunc, middlewareFunc...)
 }
 
-func (rg *routerGroup) Patch(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) {
-	rg.handle(name, http.MethodPatch, handleFunc, middlewareFunc...)
+func (rg *routerGroup) Patch(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodPatch, handleFunc, middlewareFunc...)
+}
+
+func (rg *routerGroup) Head(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodHead, handleFunc, middlewareFunc...)
+}
+
+func (rg *routerGroup) Options(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodOptions, handleFunc, middlewareFunc...)
+}
+
+func (rg *routerGroup) Connect(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodConnect, handleFunc, middlewareFunc...)
+}
+
+func (rg *routerGroup) Trace(name string, handleFunc HandleFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.handle(name, http.MethodTrace, handleFunc, middlewareFunc...)
 }
 
 type router struct {
 	routerGroups []*routerGroup
+	engine       *Engine
 }
 
 func (rg *router) Group(name string) *routerGroup {
+	return rg.newRouterGroup(name, joinPaths("/", name), nil)
+}
+
+func (rg *router) newRouterGroup(name string, basePath string, parent *routerGroup) *routerGroup {
 	routerGroup := &routerGroup{
-		name:                     name,
-		handleFuncMap:            make(map[string]map[string]HandleFunc),
-		middlewaresHandleFuncMap: make(map[string]map[string][]MiddlewareFunc),
-		middlewares:              []MiddlewareFunc{},
+		name:     name,
+		basePath: basePath,
+		parent:   parent,
+		engine:   rg.engine,
 	}
 	rg.routerGroups = append(rg.routerGroups, routerGroup)
 	return routerGroup
@@ -94,33 +192,230 @@ func (rg *router) Group(name string) *routerGroup {
 
 type Engine struct {
 	router
-	funcMap    template.FuncMap
-	htmlRender render.HTMLRender
-	pool       sync.Pool
+	funcMap     template.FuncMap
+	htmlRender  render.HTMLRender
+	pool        sync.Pool
+	trees       map[string]*treeNode
+	maxParams   int
+	handlers    HandlersChain
+	noRoute     HandlersChain
+	noMethod    HandlersChain
+	allNoRoute  HandlersChain
+	allNoMethod HandlersChain
+	namedRoutes map[string]*Route
+	routes      []*Route
+
+	// RedirectTrailingSlash redirects a request to the path with the
+	// trailing slash added or removed, if only that one is registered.
+	RedirectTrailingSlash bool
+	// RedirectFixedPath redirects a request to its cleaned path, matched
+	// case-insensitively, e.g. /API//Users/../users to /api/users.
+	RedirectFixedPath bool
+	// RemoveExtraSlash routes a request by its cleaned path without
+	// redirecting, so /api//users is served by /api/users.
+	RemoveExtraSlash bool
+	// UseProblemDetails makes the built-in 404, 405 and 500 responses and
+	// the default ErrorHandler write RFC 9457 problem details documents.
+	UseProblemDetails bool
+	// ErrorHandler answers the errors collected with Context.Error. It runs
+	// once, as soon as the last handler of the chain returns or a handler
+	// aborts the chain, before the middlewares resume after their call to
+	// Next. Errors collected later, by the middlewares, are answered when the
+	// chain returns. Set it to nil to handle the errors yourself.
+	ErrorHandler HandleFunc
+	// MaxBodyBytes limits the size of the request body read by
+	// Context.GetRawData and Context.ShouldBindBodyWith. A limit of 0 or
+	// less reads the body whatever its size.
+	MaxBodyBytes int64
 }
 
 func New() *Engine {
 	e := &Engine{
-		router: router{},
+		trees:        make(map[string]*treeNode),
+		namedRoutes:  make(map[string]*Route),
+		ErrorHandler: defaultErrorHandler,
+		MaxBodyBytes: defaultMaxBodyBytes,
+	}
+	e.router = router{
+		engine: e,
 	}
 	e.pool.New = func() any {
 		return e.allocateContext()
 	}
+	e.rebuildFallbackHandlers()
+	return e
+}
+
+// Default returns an Engine with the Logging and Recovery middlewares.
+func Default() *Engine {
+	e := New()
+	e.Use(Logging, Recovery())
 	return e
 }
 
 func (e *Engine) allocateContext() any {
 	return &Context{
 		engine: e,
+		params: make(Params, 0, e.maxParams),
+	}
+}
+
+// addRoute compiles a route into the tree of its method, so that a request
+// is dispatched by a single tree lookup regardless of how many groups and
+// routes are registered.
+func (e *Engine) addRoute(method string, path string, handlers HandlersChain) {
+	tree, ok := e.trees[method]
+	if !ok {
+		tree = newTreeNode()
+		e.trees[method] = tree
+	}
+
+	node := tree.Put(path)
+	if node.handlers != nil {
+		panic("err: register same route")
+	}
+	node.handlers = handlers
+
+	if n := countParams(path); n > e.maxParams {
+		e.maxParams = n
+	}
+}
+
+func (e *Engine) getRoute(method string, path string, ctx *Context) *treeNode {
+	tree, ok := e.trees[method]
+	if !ok {
+		return nil
+	}
+	ctx.params = ctx.params[:0]
+	return tree.Get(path, &ctx.params)
+}
+
+// allowedMethods returns the sorted methods that path can be requested
+// with, including the HEAD and OPTIONS methods answered automatically.
+func (e *Engine) allowedMethods(path string, ctx *Context) []string {
+	var methods []string
+	for method := range e.trees {
+		if method == AnyMethod {
+			continue
+		}
+		if e.getRoute(method, path, ctx) != nil {
+			methods = append(methods, method)
+		}
+	}
+	ctx.params = ctx.params[:0]
+	if len(methods) == 0 {
+		return nil
+	}
+
+	contains := func(method string) bool {
+		for _, m := range methods {
+			if m == method {
+				return true
+			}
+		}
+		return false
+	}
+	if contains(http.MethodGet) && !contains(http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	if !contains(http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Use registers global middlewares. They run before the group middlewares
+// of every route registered after Use, and also on the NoRoute and NoMethod
+// responses.
+func (e *Engine) Use(middlewareFuncs ...MiddlewareFunc) {
+	e.UseHandlers(wrapMiddlewares(middlewareFuncs)...)
+}
+
+// UseHandlers registers global handlers that drive the chain themselves
+// with Context.Next and Context.Abort.
+func (e *Engine) UseHandlers(handles ...HandleFunc) {
+	e.handlers = append(e.handlers, handles...)
+	e.rebuildFallbackHandlers()
+}
+
+// NoRoute sets the handlers called when no route matches the request path.
+func (e *Engine) NoRoute(handles ...HandleFunc) {
+	e.noRoute = handles
+	e.rebuildFallbackHandlers()
+}
+
+// NoMethod sets the handlers called when the request path matches a route
+// registered for other methods only. The Allow header is already set when
+// they run.
+func (e *Engine) NoMethod(handles ...HandleFunc) {
+	e.noMethod = handles
+	e.rebuildFallbackHandlers()
+}
+
+func (e *Engine) rebuildFallbackHandlers() {
+	e.allNoRoute = combineHandlers(e.handlers, e.noRouteHandlers())
+	e.allNoMethod = combineHandlers(e.handlers, e.noMethodHandlers())
+}
+
+func (e *Engine) noRouteHandlers() HandlersChain {
+	if len(e.noRoute) == 0 {
+		return HandlersChain{defaultNoRoute}
+	}
+	return e.noRoute
+}
+
+func (e *Engine) noMethodHandlers() HandlersChain {
+	if len(e.noMethod) == 0 {
+		return HandlersChain{defaultNoMethod}
+	}
+	return e.noMethod
+}
+
+func defaultNoRoute(ctx *Context) {
+	serveError(ctx, http.StatusNotFound, fmt.Sprintf("%s %s not found", ctx.R.RequestURI, ctx.R.Method))
+}
+
+func defaultNoMethod(ctx *Context) {
+	serveError(ctx, http.StatusMethodNotAllowed, fmt.Sprintf("%s %s not allow", ctx.R.RequestURI, ctx.R.Method))
+}
+
+func defaultOptions(ctx *Context) {
+	ctx.W.WriteHeader(http.StatusNoContent)
+}
+
+// serveNoRoute replaces the rest of the chain with the NoRoute handlers,
+// for handlers that find out they have nothing to serve.
+func (e *Engine) serveNoRoute(ctx *Context) {
+	ctx.W.WriteHeader(http.StatusNotFound)
+	ctx.handlers = e.noRouteHandlers()
+	ctx.index = -1
+	ctx.Next()
+}
+
+func (e *Engine) handleHTTPRequest(ctx *Context, handlers HandlersChain) {
+	ctx.handlers = handlers
+	ctx.Next()
+	e.handleErrors(ctx)
+}
+
+func (e *Engine) handleErrors(ctx *Context) {
+	if e.ErrorHandler == nil || ctx.errorsHandled || len(ctx.Errors) == 0 {
+		return
 	}
+	ctx.errorsHandled = true
+	e.ErrorHandler(ctx)
 }
 
 func (e *Engine) SetFuncMap(funcMap template.FuncMap) {
 	e.funcMap = funcMap
 }
 
+// LoadTemplate parses the templates matching pattern. Besides the functions
+// set by SetFuncMap, templates can call url to build the path of a named
+// route, e.g. {{ url "user.show" .ID }}.
 func (e *Engine) LoadTemplate(pattern string) {
-	t := template.Must(template.New("").Funcs(e.funcMap).ParseGlob(pattern))
+	t := template.Must(template.New("").Funcs(template.FuncMap{"url": e.URL}).Funcs(e.funcMap).ParseGlob(pattern))
 	e.SetHTMLTemplate(t)
 }
 
@@ -132,42 +427,180 @@ func (e *Engine) SetHTMLTemplate(t *template.Template) {
 
 func (e *Engine) httpRequestHandle(ctx *Context, w http.ResponseWriter, r *http.Request) {
 	method := r.Method
+	path := r.URL.Path
+	if e.RemoveExtraSlash {
+		path = cleanPath(path)
+	}
+
+	if node, treeMethod := e.matchRoute(method, path, ctx); node != nil {
+		if treeMethod == http.MethodGet && method == http.MethodHead {
+			ctx.W = &headResponseWriter{ResponseWriter: ctx.W}
+			e.handleHTTPRequest(ctx, node.handlers)
+			ctx.W = &ctx.writermem
+			return
+		}
+		e.handleHTTPRequest(ctx, node.handlers)
+		return
+	}
+
+	if method != http.MethodConnect && path != "/" {
+		if location, ok := e.redirectPath(method, path, ctx); ok {
+			e.redirect(ctx, location)
+			return
+		}
+	}
+
+	if methods := e.allowedMethods(path, ctx); len(methods) > 0 {
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		if method == http.MethodOptions {
+			e.handleHTTPRequest(ctx, combineHandlers(e.handlers, HandlersChain{defaultOptions}))
+			return
+		}
+		ctx.W.WriteHeader(http.StatusMethodNotAllowed)
+		e.handleHTTPRequest(ctx, e.allNoMethod)
+		return
+	}
+
+	ctx.W.WriteHeader(http.StatusNotFound)
+	e.handleHTTPRequest(ctx, e.allNoRoute)
+}
+
+// matchRoute looks path up in the trees of method, of Any and, for HEAD,
+// of GET, and returns the most specific route with the method of its tree,
+// so an Any catch-all does not hide a static route of the method. On a tie
+// the tree searched first wins. ctx.params holds the parameters of the
+// returned route.
+func (e *Engine) matchRoute(method string, path string, ctx *Context) (*treeNode, string) {
+	candidates := [3]string{method, AnyMethod, http.MethodGet}
+	n := 2
+	if method == http.MethodHead {
+		n = 3
+	}
+
+	var best *treeNode
+	bestIndex, searched := -1, -1
+	for i, m := range candidates[:n] {
+		tree, ok := e.trees[m]
+		if !ok {
+			continue
+		}
+		ctx.params = ctx.params[:0]
+		node := tree.Get(path, &ctx.params)
+		searched = i
+		if node != nil && (best == nil || morePrecise(node.routerName, best.routerName)) {
+			best, bestIndex = node, i
+		}
+	}
+	if best == nil {
+		return nil, ""
+	}
+	if bestIndex != searched {
+		e.getRoute(candidates[bestIndex], path, ctx)
+	}
+	return best, candidates[bestIndex]
+}
+
+// morePrecise reports whether the pattern a takes precedence over b for a
+// path they both match: at the first segment where they differ, static
+// segments win over named parameters, which win over catch-all segments.
+func morePrecise(a string, b string) bool {
+	for a != "" && b != "" {
+		var segA, segB string
+		segA, a = nextSegment(a)
+		segB, b = nextSegment(b)
+		if rankA, rankB := segmentRank(segA), segmentRank(segB); rankA != rankB {
+			return rankA < rankB
+		}
+	}
+	return false
+}
 
-	for _, group := range e.routerGroups {
-		for name, handleFuncMap := range group.handleFuncMap {
-			url := "/" + group.name + name
-			if r.URL.Path == url {
-				if handle, ok := handleFuncMap[AnyMethod]; ok {
-					group.methodHandle(ctx, name, AnyMethod, handle)
-					return
-				}
+func segmentRank(segment string) int {
+	switch {
+	case strings.HasPrefix(segment, ":"):
+		return 1
+	case strings.HasPrefix(segment, "*"):
+		return 2
+	default:
+		return 0
+	}
+}
 
-				if handle, ok := handleFuncMap[method]; ok {
-					group.methodHandle(ctx, name, method, handle)
-					return
-				}
+func (e *Engine) hasRoute(method string, path string, ctx *Context) bool {
+	found := e.getRoute(AnyMethod, path, ctx) != nil || e.getRoute(method, path, ctx) != nil ||
+		method == http.MethodHead && e.getRoute(http.MethodGet, path, ctx) != nil
+	ctx.params = ctx.params[:0]
+	return found
+}
 
-				w.WriteHeader(http.StatusMethodNotAllowed)
-				fmt.Fprintf(w, "%s %s not allow", r.RequestURI, method)
+func (e *Engine) findCaseInsensitivePath(method string, path string) (string, bool) {
+	methods := []string{AnyMethod, method}
+	if method == http.MethodHead {
+		methods = append(methods, http.MethodGet)
+	}
+	for _, m := range methods {
+		if tree, ok := e.trees[m]; ok {
+			if fixed, ok := tree.FindCaseInsensitive(path); ok {
+				return fixed, true
+			}
+		}
+	}
+	return "", false
+}
 
-				return
+// redirectPath returns the canonical path to redirect a request for a path
+// without route to, according to RedirectTrailingSlash and
+// RedirectFixedPath.
+func (e *Engine) redirectPath(method string, path string, ctx *Context) (string, bool) {
+	if e.RedirectTrailingSlash {
+		if fixed := toggleTrailingSlash(path); e.hasRoute(method, fixed, ctx) {
+			return fixed, true
+		}
+	}
+
+	if e.RedirectFixedPath {
+		cleaned := cleanPath(path)
+		if fixed, ok := e.findCaseInsensitivePath(method, cleaned); ok {
+			return fixed, true
+		}
+		if e.RedirectTrailingSlash {
+			if fixed, ok := e.findCaseInsensitivePath(method, toggleTrailingSlash(cleaned)); ok {
+				return fixed, true
 			}
 		}
+	}
 
-		w.WriteHeader(http.StatusNotFound)
-		fmt.Fprintf(w, "%s %s not found", r.RequestURI, method)
+	return "", false
+}
+
+// redirect answers with 301 Moved Permanently for GET and HEAD requests,
+// and with 308 Permanent Redirect for other methods so the method and body
+// are preserved.
+func (e *Engine) redirect(ctx *Context, location string) {
+	status := http.StatusPermanentRedirect
+	if ctx.R.Method == http.MethodGet || ctx.R.Method == http.MethodHead {
+		status = http.StatusMovedPermanently
 	}
+	if ctx.R.URL.RawQuery != "" {
+		location += "?" + ctx.R.URL.RawQuery
+	}
+
+	e.handleHTTPRequest(ctx, combineHandlers(e.handlers, HandlersChain{func(ctx *Context) {
+		ctx.W.Header().Set("Location", location)
+		ctx.W.WriteHeader(status)
+	}}))
 }
 
 func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := e.pool.Get().(*Context)
-	ctx.W = w
-	ctx.R = r
+	ctx.reset(w, r)
 	e.httpRequestHandle(ctx, w, r)
+	ctx.W.WriteHeaderNow()
 	e.pool.Put(ctx)
 }
 
 func (e *Engine) Run() {
+	debugPrint("Listening and serving HTTP on :8111\n")
 	if err := http.ListenAndServe(":8111", e); err != nil {
 		log.Fatal(err)
 	}
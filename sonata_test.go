@@ -0,0 +1,282 @@
+package sonata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func performRequest(e *Engine, method string, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func stringHandler(body string) HandleFunc {
+	return func(ctx *Context) {
+		ctx.String(http.StatusOK, body)
+	}
+}
+
+func TestAnyRoutePrecedence(t *testing.T) {
+	e := New()
+	g := e.Group("")
+	g.Get("/api/users", stringHandler("users"))
+	g.Get("/api/:resource", stringHandler("resource"))
+	g.Any("/api/users/:id", stringHandler("any user"))
+	g.Get("/api/users/:id", stringHandler("get user"))
+	g.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("spa"))
+	}))
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/api/users", "users"},
+		{http.MethodHead, "/api/users", ""},
+		{http.MethodGet, "/api/orders", "resource"},
+		{http.MethodPost, "/api/users", "spa"},
+		{http.MethodGet, "/api/users/1", "get user"},
+		{http.MethodPost, "/api/users/1", "any user"},
+		{http.MethodGet, "/", "spa"},
+		{http.MethodGet, "/index.html", "spa"},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, tt.method, tt.path)
+		if w.Code != http.StatusOK || w.Body.String() != tt.body {
+			t.Errorf("%s %s = %d %q, want 200 %q", tt.method, tt.path, w.Code, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestMorePrecise(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"/api/users", "/*filepath", true},
+		{"/api/users", "/api/:resource", true},
+		{"/api/:resource", "/api/users", false},
+		{"/api/:resource", "/*filepath", true},
+		{"/", "/*filepath", true},
+		{"/api/users", "/api/users", false},
+	}
+	for _, tt := range tests {
+		if got := morePrecise(tt.a, tt.b); got != tt.want {
+			t.Errorf("morePrecise(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMount(t *testing.T) {
+	e := New()
+	echoPath := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+	e.Group("admin").Mount("/", echoPath)
+	e.Group("").Mount("/api/", echoPath)
+	e.Group("").Any("/debug/*path", WrapH(echoPath))
+
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/admin", "/"},
+		{"/admin/", "/"},
+		{"/admin/users/1", "/users/1"},
+		{"/api", "/"},
+		{"/api/v1", "/v1"},
+		{"/debug/pprof/cmdline", "/debug/pprof/cmdline"},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, http.MethodGet, tt.path)
+		if w.Code != http.StatusOK || w.Body.String() != tt.body {
+			t.Errorf("GET %s = %d %q, want 200 %q", tt.path, w.Code, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestAutomaticHeadAndOptions(t *testing.T) {
+	e := New()
+	g := e.Group("")
+	g.Get("/users", func(ctx *Context) {
+		ctx.W.Header().Set("X-Handler", "get")
+		ctx.String(http.StatusOK, "users")
+	})
+	g.Post("/users", stringHandler("created"))
+	g.Head("/ping", func(ctx *Context) {
+		ctx.W.Header().Set("X-Handler", "head")
+	})
+	g.Options("/custom", stringHandler("custom options"))
+
+	tests := []struct {
+		method  string
+		path    string
+		status  int
+		body    string
+		allow   string
+		handler string
+	}{
+		{http.MethodHead, "/users", http.StatusOK, "", "", "get"},
+		{http.MethodHead, "/ping", http.StatusOK, "", "", "head"},
+		{http.MethodOptions, "/users", http.StatusNoContent, "", "GET, HEAD, OPTIONS, POST", ""},
+		{http.MethodOptions, "/custom", http.StatusOK, "custom options", "", ""},
+		{http.MethodDelete, "/users", http.StatusMethodNotAllowed, "/users DELETE not allow", "GET, HEAD, OPTIONS, POST", ""},
+		{http.MethodHead, "/missing", http.StatusNotFound, "/missing HEAD not found", "", ""},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, tt.method, tt.path)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+		if allow := w.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, allow, tt.allow)
+		}
+		if handler := w.Header().Get("X-Handler"); handler != tt.handler {
+			t.Errorf("%s %s X-Handler = %q, want %q", tt.method, tt.path, handler, tt.handler)
+		}
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	e := New()
+	e.RedirectTrailingSlash = true
+	e.RedirectFixedPath = true
+	g := e.Group("")
+	g.Get("/api/users", stringHandler("users"))
+	g.Get("/docs/", stringHandler("docs"))
+	g.Post("/api/orders", stringHandler("orders"))
+
+	tests := []struct {
+		method   string
+		path     string
+		status   int
+		location string
+	}{
+		{http.MethodGet, "/api/users/", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodGet, "/docs", http.StatusMovedPermanently, "/docs/"},
+		{http.MethodGet, "/api/users/?page=2", http.StatusMovedPermanently, "/api/users?page=2"},
+		{http.MethodHead, "/api/users/", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodPost, "/api/orders/", http.StatusPermanentRedirect, "/api/orders"},
+		{http.MethodGet, "/API//Users/../users", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodGet, "/API/USERS/", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodGet, "/api/users", http.StatusOK, ""},
+		{http.MethodGet, "/api/missing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, tt.method, tt.path)
+		if w.Code != tt.status || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, w.Code, w.Header().Get("Location"), tt.status, tt.location)
+		}
+	}
+}
+
+func TestRemoveExtraSlash(t *testing.T) {
+	e := New()
+	e.RemoveExtraSlash = true
+	e.Group("").Get("/api/users", stringHandler("users"))
+
+	w := performRequest(e, http.MethodGet, "/api//users")
+	if w.Code != http.StatusOK || w.Body.String() != "users" {
+		t.Errorf("GET /api//users = %d %q, want 200 %q", w.Code, w.Body.String(), "users")
+	}
+}
+
+func TestRedirectsDisabled(t *testing.T) {
+	e := New()
+	e.RedirectTrailingSlash = false
+	e.RedirectFixedPath = false
+	e.Group("").Get("/api/users", stringHandler("users"))
+
+	for _, path := range []string{"/api/users/", "/API/users"} {
+		if w := performRequest(e, http.MethodGet, path); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, w.Code)
+		}
+	}
+}
+
+func TestNoRouteAndNoMethod(t *testing.T) {
+	e := New()
+	e.NoRoute(func(ctx *Context) {
+		ctx.W.Write([]byte("no route"))
+	})
+	e.NoMethod(func(ctx *Context) {
+		ctx.W.Write([]byte("no method, allow " + ctx.W.Header().Get("Allow")))
+	})
+	// Registered after NoRoute and NoMethod, the middleware still runs
+	// on their chains.
+	e.Use(func(next HandleFunc) HandleFunc {
+		return func(ctx *Context) {
+			ctx.W.Header().Set("X-Global", "1")
+			next(ctx)
+		}
+	})
+	g := e.Group("")
+	g.Get("/users/:id", stringHandler("user"))
+	g.Put("/users/:id", stringHandler("updated"))
+	g.Delete("/users/:id", stringHandler("deleted"))
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+		allow  string
+	}{
+		{http.MethodPost, "/users/1", http.StatusMethodNotAllowed, "no method, allow DELETE, GET, HEAD, OPTIONS, PUT", "DELETE, GET, HEAD, OPTIONS, PUT"},
+		{http.MethodGet, "/missing", http.StatusNotFound, "no route", ""},
+		{http.MethodPost, "/users", http.StatusNotFound, "no route", ""},
+		{http.MethodOptions, "/users/1", http.StatusNoContent, "", "DELETE, GET, HEAD, OPTIONS, PUT"},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, tt.method, tt.path)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+		if allow := w.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, allow, tt.allow)
+		}
+		if w.Header().Get("X-Global") != "1" {
+			t.Errorf("%s %s: the global middleware did not run", tt.method, tt.path)
+		}
+	}
+}
+
+func TestGroupPrefixAndMiddlewares(t *testing.T) {
+	var trace []string
+	e := New()
+	e.Use(traceMiddleware(&trace, "global"))
+	api := e.Group("api")
+	api.Use(traceMiddleware(&trace, "api"))
+	v1 := api.Group("/v1/", traceMiddleware(&trace, "v1"))
+	v1.Get("/users", func(ctx *Context) {
+		trace = append(trace, "handler")
+		ctx.String(http.StatusOK, "users")
+	}, traceMiddleware(&trace, "route"))
+	v1.Group("admin").Get("", stringHandler("admin"))
+	api.Get("/", stringHandler("api"))
+
+	for path, body := range map[string]string{
+		"/api/v1/users": "users",
+		"/api/v1/admin": "admin",
+		"/api/":         "api",
+	} {
+		if w := performRequest(e, http.MethodGet, path); w.Code != http.StatusOK || w.Body.String() != body {
+			t.Errorf("GET %s = %d %q, want 200 %q", path, w.Code, w.Body.String(), body)
+		}
+	}
+
+	trace = nil
+	performRequest(e, http.MethodGet, "/api/v1/users")
+	want := []string{
+		"global before", "api before", "v1 before", "route before",
+		"handler",
+		"route after", "v1 after", "api after", "global after",
+	}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
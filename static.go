@@ -0,0 +1,95 @@
+package sonata
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+const staticParam = "filepath"
+
+// Dir returns the file system of the directory root. Directories without
+// an index.html are listed only if listDirectory is true.
+func Dir(root string, listDirectory bool) fs.FS {
+	fsys := os.DirFS(root)
+	if listDirectory {
+		return fsys
+	}
+	return OnlyFilesFS(fsys)
+}
+
+// OnlyFilesFS disables the directory listing of fsys: a directory can only
+// be opened if it contains an index.html, which is served in its place.
+func OnlyFilesFS(fsys fs.FS) fs.FS {
+	return onlyFilesFS{fs: fsys}
+}
+
+type onlyFilesFS struct {
+	fs fs.FS
+}
+
+func (o onlyFilesFS) Open(name string) (fs.File, error) {
+	f, err := o.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		if _, err := fs.Stat(o.fs, path.Join(name, "index.html")); err != nil {
+			f.Close()
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return f, nil
+}
+
+// Static serves the files of the directory root under relativePath,
+// without directory listing.
+func (rg *routerGroup) Static(relativePath string, root string, middlewareFunc ...MiddlewareFunc) *Route {
+	return rg.StaticFS(relativePath, Dir(root, false), middlewareFunc...)
+}
+
+// StaticFS serves the files of fsys, e.g. an embed.FS, under relativePath.
+// Content types, index.html files and If-Modified-Since requests are handled
+// by http.FileServer; files that do not exist are answered by the NoRoute
+// handlers.
+func (rg *routerGroup) StaticFS(relativePath string, fsys fs.FS, middlewareFunc ...MiddlewareFunc) *Route {
+	if strings.ContainsAny(relativePath, ":*") {
+		panic("err: URL parameters can not be used when serving a static folder")
+	}
+
+	fileServer := http.FileServer(http.FS(fsys))
+	handle := func(ctx *Context) {
+		file := ctx.Param(staticParam)
+		name := strings.TrimPrefix(path.Clean(file), "/")
+		if name == "" {
+			name = "."
+		}
+		if _, err := fs.Stat(fsys, name); err != nil {
+			rg.engine.serveNoRoute(ctx)
+			return
+		}
+		fileServer.ServeHTTP(ctx.W, stripPrefix(ctx.R, file))
+	}
+
+	urlPattern := joinPaths(relativePath, "/*"+staticParam)
+	return rg.Get(urlPattern, handle, middlewareFunc...)
+}
+
+// StaticFile serves the single file filepath under relativePath.
+func (rg *routerGroup) StaticFile(relativePath string, filepath string, middlewareFunc ...MiddlewareFunc) *Route {
+	if strings.ContainsAny(relativePath, ":*") {
+		panic("err: URL parameters can not be used when serving a static file")
+	}
+
+	return rg.Get(relativePath, func(ctx *Context) {
+		http.ServeFile(ctx.W, ctx.R, filepath)
+	}, middlewareFunc...)
+}
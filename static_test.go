@@ -0,0 +1,89 @@
+package sonata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newStaticEngine(t *testing.T, listDirectory bool) *Engine {
+	t.Helper()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "public")
+	files := map[string]string{
+		"secret.txt":             "secret",
+		"public/style.css":       "body {}",
+		"public/docs/index.html": "<h1>docs</h1>",
+		"public/images/logo.txt": "logo",
+	}
+	for name, content := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(root, "style.css"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.NoRoute(func(ctx *Context) {
+		ctx.W.Write([]byte("custom 404"))
+	})
+	e.Group("").StaticFS("/static", Dir(root, listDirectory))
+	return e
+}
+
+func TestStatic(t *testing.T) {
+	e := newStaticEngine(t, false)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/static/style.css", http.StatusOK, "text/css; charset=utf-8", "body {}"},
+		{"/static/docs/", http.StatusOK, "text/html; charset=utf-8", "<h1>docs</h1>"},
+		{"/static/images/", http.StatusNotFound, "", "custom 404"},
+		{"/static/missing.css", http.StatusNotFound, "", "custom 404"},
+		{"/static/../secret.txt", http.StatusNotFound, "", "custom 404"},
+		{"/static/%2e%2e/secret.txt", http.StatusNotFound, "", "custom 404"},
+	}
+	for _, tt := range tests {
+		w := performRequest(e, http.MethodGet, tt.path)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("GET %s: got %d %q, want %d %q", tt.path, w.Code, w.Body, tt.status, tt.body)
+		}
+		if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("GET %s: Content-Type %q, want %q", tt.path, w.Header().Get("Content-Type"), tt.contentType)
+		}
+	}
+}
+
+func TestStaticListDirectory(t *testing.T) {
+	e := newStaticEngine(t, true)
+	w := performRequest(e, http.MethodGet, "/static/images/")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "logo.txt") {
+		t.Errorf("got %d %q, want the listing of images", w.Code, w.Body)
+	}
+}
+
+func TestStaticIfModifiedSince(t *testing.T) {
+	e := newStaticEngine(t, false)
+	req := httptest.NewRequest(http.MethodGet, "/static/style.css", nil)
+	req.Header.Set("If-Modified-Since", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("status %d, want 304", w.Code)
+	}
+}
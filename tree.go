@@ -0,0 +1,190 @@
+package sonata
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Param struct {
+	Key   string
+	Value string
+}
+
+type Params []Param
+
+func (ps Params) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+func (ps Params) ByName(name string) string {
+	value, _ := ps.Get(name)
+	return value
+}
+
+// treeNode is a node of the path prefix tree. Every level of the tree
+// matches exactly one path segment, either literally (children), as a named
+// parameter (paramChild) or as a trailing catch-all (catchAllChild).
+type treeNode struct {
+	segment       string
+	routerName    string
+	isEnd         bool
+	handlers      HandlersChain
+	children      map[string]*treeNode
+	paramChild    *treeNode
+	catchAllChild *treeNode
+}
+
+func newTreeNode() *treeNode {
+	return &treeNode{
+		children: make(map[string]*treeNode),
+	}
+}
+
+func countParams(path string) int {
+	return strings.Count(path, "/:") + strings.Count(path, "/*")
+}
+
+// nextSegment splits "/a/b" into "a" and "/b". The path must start with '/'.
+func nextSegment(path string) (string, string) {
+	path = path[1:]
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i], path[i:]
+	}
+	return path, ""
+}
+
+func (t *treeNode) Put(path string) *treeNode {
+	if path == "" || path[0] != '/' {
+		panic(fmt.Sprintf("err: path must begin with '/', got %q", path))
+	}
+
+	node := t
+	rest := path
+	for rest != "" {
+		var segment string
+		segment, rest = nextSegment(rest)
+		node = node.child(segment, rest, path)
+	}
+	node.isEnd = true
+	node.routerName = path
+	return node
+}
+
+func (t *treeNode) child(segment string, rest string, path string) *treeNode {
+	if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+		child, ok := t.children[segment]
+		if !ok {
+			child = newTreeNode()
+			child.segment = segment
+			t.children[segment] = child
+		}
+		return child
+	}
+
+	if len(segment) == 1 {
+		panic(fmt.Sprintf("err: wildcard in path %q must be named", path))
+	}
+
+	if segment[0] == ':' {
+		if t.paramChild == nil {
+			t.paramChild = newTreeNode()
+			t.paramChild.segment = segment
+		} else if t.paramChild.segment != segment {
+			panic(fmt.Sprintf("err: %q in path %q conflicts with existing wildcard %q",
+				segment, path, t.paramChild.segment))
+		}
+		return t.paramChild
+	}
+
+	if rest != "" {
+		panic(fmt.Sprintf("err: catch-all %q must be the last segment in path %q", segment, path))
+	}
+	if t.catchAllChild == nil {
+		t.catchAllChild = newTreeNode()
+		t.catchAllChild.segment = segment
+	} else if t.catchAllChild.segment != segment {
+		panic(fmt.Sprintf("err: %q in path %q conflicts with existing wildcard %q",
+			segment, path, t.catchAllChild.segment))
+	}
+	return t.catchAllChild
+}
+
+// Get returns the node registered for path and appends the captured
+// parameters to params. Static segments take precedence over named
+// parameters, which take precedence over catch-all segments.
+func (t *treeNode) Get(path string, params *Params) *treeNode {
+	if path == "" {
+		if t.isEnd {
+			return t
+		}
+		return nil
+	}
+	if path[0] != '/' {
+		return nil
+	}
+
+	segment, rest := nextSegment(path)
+	if child, ok := t.children[segment]; ok {
+		if node := child.Get(rest, params); node != nil {
+			return node
+		}
+	}
+
+	if t.paramChild != nil && segment != "" {
+		*params = append(*params, Param{Key: t.paramChild.segment[1:], Value: segment})
+		if node := t.paramChild.Get(rest, params); node != nil {
+			return node
+		}
+		*params = (*params)[:len(*params)-1]
+	}
+
+	if t.catchAllChild != nil {
+		*params = append(*params, Param{Key: t.catchAllChild.segment[1:], Value: path})
+		return t.catchAllChild
+	}
+
+	return nil
+}
+
+// FindCaseInsensitive returns the registered form of path, matching static
+// segments case-insensitively. Captured parameter values are kept as is.
+func (t *treeNode) FindCaseInsensitive(path string) (string, bool) {
+	if path == "" {
+		return "", t.isEnd
+	}
+	if path[0] != '/' {
+		return "", false
+	}
+
+	segment, rest := nextSegment(path)
+	if child, ok := t.children[segment]; ok {
+		if fixed, ok := child.FindCaseInsensitive(rest); ok {
+			return "/" + segment + fixed, true
+		}
+	}
+	for key, child := range t.children {
+		if key == segment || !strings.EqualFold(key, segment) {
+			continue
+		}
+		if fixed, ok := child.FindCaseInsensitive(rest); ok {
+			return "/" + key + fixed, true
+		}
+	}
+
+	if t.paramChild != nil && segment != "" {
+		if fixed, ok := t.paramChild.FindCaseInsensitive(rest); ok {
+			return "/" + segment + fixed, true
+		}
+	}
+
+	if t.catchAllChild != nil {
+		return path, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,192 @@
+package sonata
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+func newBenchmarkEngine(groups int, routesPerGroup int) *Engine {
+	e := New()
+	handle := func(ctx *Context) {}
+	for i := 0; i < groups; i++ {
+		g := e.Group(fmt.Sprintf("group%d", i))
+		for j := 0; j < routesPerGroup; j++ {
+			g.Get(fmt.Sprintf("/static%d", j), handle)
+			g.Get(fmt.Sprintf("/param%d/:id", j), handle)
+		}
+	}
+	return e
+}
+
+func benchmarkRouteLookup(b *testing.B, path func(groups, routes int) string) {
+	for _, size := range []int{10, 100, 1000} {
+		groups, routes := size/10, 10
+		e := newBenchmarkEngine(groups, routes)
+		r := httptest.NewRequest(http.MethodGet, path(groups, routes), nil)
+		w := &discardResponseWriter{header: http.Header{}}
+
+		b.Run(fmt.Sprintf("routes=%d", groups*routes*2), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				e.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+func BenchmarkRouteLookupStatic(b *testing.B) {
+	benchmarkRouteLookup(b, func(groups, routes int) string {
+		return fmt.Sprintf("/group%d/static%d", groups-1, routes-1)
+	})
+}
+
+func BenchmarkRouteLookupParam(b *testing.B) {
+	benchmarkRouteLookup(b, func(groups, routes int) string {
+		return fmt.Sprintf("/group%d/param%d/42", groups-1, routes-1)
+	})
+}
+
+func BenchmarkRouteLookupNotFound(b *testing.B) {
+	benchmarkRouteLookup(b, func(groups, routes int) string {
+		return "/missing/path"
+	})
+}
+
+func newTestTree(paths ...string) *treeNode {
+	tree := newTreeNode()
+	for _, path := range paths {
+		tree.Put(path)
+	}
+	return tree
+}
+
+func TestTreeGet(t *testing.T) {
+	tree := newTestTree(
+		"/",
+		"/users",
+		"/users/",
+		"/users/new",
+		"/users/:id",
+		"/users/:id/posts/:post",
+		"/users/:id/settings",
+		"/files/*filepath",
+		"/files/static/logo.png",
+		"/a/:x/c",
+		"/a/b/d",
+	)
+
+	tests := []struct {
+		path   string
+		route  string
+		params Params
+	}{
+		{"/", "/", nil},
+		{"/users", "/users", nil},
+		{"/users/", "/users/", nil},
+		{"/users/new", "/users/new", nil},
+		{"/users/42", "/users/:id", Params{{"id", "42"}}},
+		{"/users/42/posts/7", "/users/:id/posts/:post", Params{{"id", "42"}, {"post", "7"}}},
+		{"/users/new/settings", "/users/:id/settings", Params{{"id", "new"}}},
+		{"/files/css/app.css", "/files/*filepath", Params{{"filepath", "/css/app.css"}}},
+		{"/files/", "/files/*filepath", Params{{"filepath", "/"}}},
+		{"/files/static/logo.png", "/files/static/logo.png", nil},
+		{"/files/static/other.png", "/files/*filepath", Params{{"filepath", "/static/other.png"}}},
+		// The static segment b matches first, but only the parameter
+		// leads to a route, so the lookup backtracks.
+		{"/a/b/c", "/a/:x/c", Params{{"x", "b"}}},
+		{"/a/b/d", "/a/b/d", nil},
+		{"/users/42/posts", "", nil},
+		{"/missing", "", nil},
+		{"users", "", nil},
+	}
+	for _, tt := range tests {
+		var params Params
+		node := tree.Get(tt.path, &params)
+		route := ""
+		if node != nil {
+			route = node.routerName
+		}
+		if route != tt.route {
+			t.Errorf("Get(%q) matched %q, want %q", tt.path, route, tt.route)
+			continue
+		}
+		if fmt.Sprint(params) != fmt.Sprint(tt.params) {
+			t.Errorf("Get(%q) params = %v, want %v", tt.path, params, tt.params)
+		}
+	}
+}
+
+func TestTreePutPanics(t *testing.T) {
+	tests := []struct {
+		existing string
+		path     string
+	}{
+		{"", "users"},
+		{"", ""},
+		{"", "/users/:"},
+		{"", "/files/*"},
+		{"", "/files/*filepath/more"},
+		{"/users/:id", "/users/:name"},
+		{"/files/*filepath", "/files/*path"},
+	}
+	for _, tt := range tests {
+		tree := newTreeNode()
+		if tt.existing != "" {
+			tree.Put(tt.existing)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Put(%q) after %q did not panic", tt.path, tt.existing)
+				}
+			}()
+			tree.Put(tt.path)
+		}()
+	}
+}
+
+func TestTreePutSameWildcard(t *testing.T) {
+	tree := newTestTree("/users/:id", "/users/:id/posts")
+	var params Params
+	if node := tree.Get("/users/1/posts", &params); node == nil || node.routerName != "/users/:id/posts" {
+		t.Errorf("Get(/users/1/posts) = %v, want /users/:id/posts", node)
+	}
+}
+
+func TestTreeFindCaseInsensitive(t *testing.T) {
+	tree := newTestTree("/API/Users", "/api/users/:id/Posts", "/static/*filepath")
+
+	tests := []struct {
+		path  string
+		fixed string
+		found bool
+	}{
+		{"/api/users", "/API/Users", true},
+		{"/API/USERS", "/API/Users", true},
+		{"/api/users/AbC/posts", "/api/users/AbC/Posts", true},
+		{"/STATIC/Css/App.css", "/static/Css/App.css", true},
+		{"/api/orders", "", false},
+	}
+	for _, tt := range tests {
+		fixed, found := tree.FindCaseInsensitive(tt.path)
+		if fixed != tt.fixed || found != tt.found {
+			t.Errorf("FindCaseInsensitive(%q) = %q, %v, want %q, %v", tt.path, fixed, found, tt.fixed, tt.found)
+		}
+	}
+}
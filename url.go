@@ -0,0 +1,83 @@
+package sonata
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrRouteNotFound = errors.New("route not found")
+
+type Route struct {
+	Method   string
+	Path     string
+	engine   *Engine
+	handlers HandlersChain
+}
+
+// Name registers the route under name, so its path can be built with
+// Engine.URL:
+//
+//	g.Get("/users/:id", show).Name("user.show")
+func (r *Route) Name(name string) *Route {
+	if _, ok := r.engine.namedRoutes[name]; ok {
+		panic(fmt.Sprintf("err: route name %q is already registered", name))
+	}
+	r.engine.namedRoutes[name] = r
+	return r
+}
+
+// URL builds the path of the route registered as name. The leading params
+// fill the path parameters of the route in order, the remaining ones are
+// encoded as query string key/value pairs:
+//
+//	e.URL("user.show", 42, "tab", "profile") // "/users/42?tab=profile"
+func (e *Engine) URL(name string, params ...any) (string, error) {
+	route, ok := e.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+	}
+
+	var b strings.Builder
+	rest := route.Path
+	for rest != "" {
+		var segment string
+		segment, rest = nextSegment(rest)
+		b.WriteByte('/')
+
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			b.WriteString(segment)
+			continue
+		}
+		if len(params) == 0 {
+			return "", fmt.Errorf("missing value of %q to build route %s", segment, name)
+		}
+
+		value := fmt.Sprint(params[0])
+		params = params[1:]
+		if segment[0] == ':' {
+			b.WriteString(url.PathEscape(value))
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for i, part := range parts {
+			parts[i] = url.PathEscape(part)
+		}
+		b.WriteString(strings.Join(parts, "/"))
+	}
+
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("odd number of query values to build route %s", name)
+	}
+	if len(params) > 0 {
+		query := url.Values{}
+		for i := 0; i < len(params); i += 2 {
+			query.Add(fmt.Sprint(params[i]), fmt.Sprint(params[i+1]))
+		}
+		b.WriteByte('?')
+		b.WriteString(query.Encode())
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,78 @@
+package sonata
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	e := New()
+	g := e.Group("")
+	g.Get("/users/:id", stringHandler("user")).Name("user.show")
+	g.Get("/users/:id/posts/:post", stringHandler("post")).Name("post.show")
+	g.Get("/files/*filepath", stringHandler("file")).Name("files")
+	g.Get("/about", stringHandler("about")).Name("about")
+
+	tests := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"user.show", []any{42}, "/users/42"},
+		{"user.show", []any{"a b/c"}, "/users/a%20b%2Fc"},
+		{"post.show", []any{1, "hello-world", "page", 2}, "/users/1/posts/hello-world?page=2"},
+		{"files", []any{"css/site main.css"}, "/files/css/site%20main.css"},
+		{"files", []any{"/js/app.js"}, "/files/js/app.js"},
+		{"about", []any{"q", "a&b", "q", "c"}, "/about?q=a%26b&q=c"},
+	}
+	for _, tt := range tests {
+		got, err := e.URL(tt.name, tt.params...)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%s, %v) = %q, %v, want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+
+	if _, err := e.URL("missing"); !errors.Is(err, ErrRouteNotFound) {
+		t.Errorf("URL of an unknown name = %v, want ErrRouteNotFound", err)
+	}
+	if _, err := e.URL("post.show", 1); err == nil {
+		t.Error("URL with a missing param should fail")
+	}
+	if _, err := e.URL("user.show", 1, "page"); err == nil {
+		t.Error("URL with an odd number of query values should fail")
+	}
+}
+
+func TestRouteNameDuplicate(t *testing.T) {
+	e := New()
+	g := e.Group("")
+	g.Get("/a", stringHandler("a")).Name("same")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+	g.Get("/b", stringHandler("b")).Name("same")
+}
+
+func TestTemplateURLFunc(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "link.html"), []byte(`<a href="{{ url "user.show" .ID "tab" "posts" }}">user</a>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.LoadTemplate(filepath.Join(dir, "*.html"))
+	g := e.Group("")
+	g.Get("/users/:id", func(ctx *Context) {
+		ctx.Template("link.html", map[string]any{"ID": ctx.Param("id")})
+	}).Name("user.show")
+
+	w := performRequest(e, http.MethodGet, "/users/7")
+	if want := `<a href="/users/7?tab=posts">user</a>`; w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+}
@@ -1 +1,17 @@
 package sonata
+
+import "path"
+
+// joinPaths joins relativePath to absolutePath, keeping the trailing slash
+// of relativePath.
+func joinPaths(absolutePath string, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	finalPath := path.Join(absolutePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}